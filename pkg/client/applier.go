@@ -21,6 +21,8 @@ import (
 	"slices"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -35,8 +37,11 @@ import (
 	"github.com/mia-platform/jpl/pkg/mutator"
 	"github.com/mia-platform/jpl/pkg/poller"
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
 	"github.com/mia-platform/jpl/pkg/runner/task"
+	"github.com/mia-platform/jpl/pkg/telemetry"
+	"github.com/mia-platform/jpl/pkg/util"
 )
 
 // Applier can be used for appling a list of resources to a remote api-server
@@ -52,6 +57,10 @@ type Applier struct {
 	filters    []filter.Interface
 
 	poller poller.StatusPoller
+
+	tracerProvider trace.TracerProvider
+	metrics        *telemetry.Metrics
+	retryPolicy    *retry.Policy
 }
 
 // ApplierOptions options for the apply step
@@ -60,6 +69,33 @@ type ApplierOptions struct {
 	DisableWait  bool
 	Timeout      time.Duration
 	FieldManager string
+
+	// Concurrency is the maximum number of objects of the same dependency group that will be applied at the same
+	// time, values lower than 1 will apply them one at a time
+	Concurrency int
+
+	// FailFast will stop applying and pruning objects after the first object that fails to be applied
+	FailFast bool
+
+	// DisableForceConflicts will make the apply of an object fail if it will change fields owned by other field
+	// managers, the conflicting fields are reported in the apply event
+	DisableForceConflicts bool
+
+	// ReplaceOnImmutable will delete and create again all the objects that fail to be applied for changes to
+	// immutable fields, otherwise only the ones annotated with resource.ReplaceAnnotation are replaced
+	ReplaceOnImmutable bool
+
+	// AdoptionPolicy determine if objects already present on the remote server and not owned by the inventory
+	// can be applied
+	AdoptionPolicy inventory.AdoptionPolicy
+
+	// Preview will only report the changes that will be made to the remote objects via TypeDiff events,
+	// without modifying them or the inventory
+	Preview bool
+
+	// SkipWaitUnchanged will not wait for the objects that have not been changed by the apply and whose status
+	// is already current
+	SkipWaitUnchanged bool
 }
 
 // Run will apply the passed objects to a remote api-server
@@ -69,6 +105,9 @@ func (a *Applier) Run(ctx context.Context, objects []*unstructured.Unstructured,
 	go func() {
 		defer close(eventChannel)
 
+		ctx, span := a.startSpan(ctx, "apply", options.DryRun)
+		defer span.End()
+
 		applierCtx := ctx
 		if options.Timeout > 0 {
 			var cancel context.CancelFunc
@@ -76,69 +115,109 @@ func (a *Applier) Run(ctx context.Context, objects []*unstructured.Unstructured,
 			defer cancel()
 		}
 
-		resourceCache := cache.NewCachedResourceGetter(a.mapper, a.client)
-		remoteObjects, err := a.loadObjectsFromInventory(applierCtx, resourceCache)
+		queueBuilder, queueOptions, err := a.prepareQueue(contextWithWarningEvents(contextWithRetryEvents(applierCtx, eventChannel), eventChannel), objects, options)
 		if err != nil {
-			handleError(eventChannel, err)
+			handleError(applierCtx, eventChannel, err)
 			return
 		}
 
-		generatedObject, abort := a.generateObjects(objects, eventChannel, resourceCache)
-		if abort {
-			return
-		}
-
-		objects = append(objects, generatedObject...)
-		if abort := a.mutateObjects(objects, eventChannel, resourceCache); abort {
+		tasks, err := queueBuilder.buildTasks(queueOptions)
+		if err != nil {
+			handleError(applierCtx, eventChannel, err)
 			return
 		}
 
-		objectsToPrune := findObjectsToPrune(remoteObjects, objects)
-		manager := inventory.NewManager(a.inventory, remoteObjects)
-
-		queueBuilder := QueueBuilder{
-			Client:       a.client,
-			Mapper:       a.mapper,
-			Manager:      manager,
-			RemoteGetter: resourceCache,
-			InfoFetcher:  a.infoFetcher,
-			Filters:      a.filters,
-			Poller:       a.poller,
-		}
-		queueOptions := QueueOptions{
-			DryRun:       options.DryRun,
-			Wait:         !options.DisableWait,
-			Prune:        true,
-			FieldManager: options.FieldManager,
-		}
-
 		contextState := &RunnerState{
 			eventChannel: eventChannel,
-			manager:      manager,
+			manager:      queueBuilder.Manager,
 			context:      applierCtx,
+
+			skipWaitUnchanged: options.SkipWaitUnchanged,
 		}
 
-		tasksQueue, err := queueBuilder.
-			WithObjects(objects).
-			WithPruneObjects(objectsToPrune).
-			Build(queueOptions)
+		// the preview will only report the changes without executing the steps
+		if !options.Preview {
+			eventChannel <- queueEvent(stepsFromTasks(tasks))
+		}
 
-		if err != nil {
-			handleError(eventChannel, err)
-			return
+		for _, e := range invalidCRDWarnings(queueBuilder.objects) {
+			eventChannel <- e
 		}
 
-		if err := a.runner.RunWithQueue(contextState, tasksQueue); err != nil {
-			handleError(eventChannel, err)
+		if err := a.runner.RunWithQueue(contextState, tasksQueue(tasks)); err != nil {
+			handleError(applierCtx, eventChannel, err)
 		}
 	}()
 
 	return eventChannel
 }
 
+// startSpan start the root span of a run called name, using the TracerProvider of the Applier, all the spans
+// of the run will be created as its children
+func (a *Applier) startSpan(ctx context.Context, name string, dryRun bool) (context.Context, trace.Span) {
+	return a.tracerProvider.Tracer(telemetry.TracerName).Start(ctx, name, trace.WithAttributes(
+		attribute.String("jpl.inventory.id", inventory.IDOf(a.inventory)),
+		attribute.Bool("jpl.dry_run", dryRun),
+	))
+}
+
+// prepareQueue load the objects tracked by the inventory and run the generators and mutators on objects, then
+// return a QueueBuilder with the objects to apply and prune, and the options for building its queue
+func (a *Applier) prepareQueue(ctx context.Context, objects []*unstructured.Unstructured, options ApplierOptions) (*QueueBuilder, QueueOptions, error) {
+	resourceCache := cache.NewCachedResourceGetter(a.mapper, a.client).WithRetryPolicy(a.retryPolicy)
+	remoteObjects, err := a.loadObjectsFromInventory(ctx, resourceCache)
+	if err != nil {
+		return nil, QueueOptions{}, err
+	}
+
+	generatedObject, err := a.generateObjects(objects, resourceCache)
+	if err != nil {
+		return nil, QueueOptions{}, err
+	}
+
+	objects = append(objects, generatedObject...)
+	if err := a.mutateObjects(objects, resourceCache); err != nil {
+		return nil, QueueOptions{}, err
+	}
+
+	manager := inventory.NewManager(a.inventory, remoteObjects)
+	manager.RetryPolicy = a.retryPolicy
+	queueBuilder := &QueueBuilder{
+		Client:       a.client,
+		Mapper:       a.mapper,
+		Manager:      manager,
+		RemoteGetter: resourceCache,
+		InfoFetcher:  a.infoFetcher,
+		Filters:      a.filters,
+		Poller:       a.poller,
+		Metrics:      a.metrics,
+		RetryPolicy:  a.retryPolicy,
+	}
+	queueBuilder.
+		WithObjects(objects).
+		WithPruneObjects(findObjectsToPrune(remoteObjects, objects))
+
+	queueOptions := QueueOptions{
+		DryRun:         options.DryRun,
+		Wait:           !options.DisableWait,
+		Prune:          true,
+		Preview:        options.Preview,
+		FieldManager:   options.FieldManager,
+		Concurrency:    options.Concurrency,
+		InventoryID:    inventory.IDOf(a.inventory),
+		AdoptionPolicy: options.AdoptionPolicy,
+		FailFast:       options.FailFast,
+
+		DisableForceConflicts: options.DisableForceConflicts,
+		ReplaceOnImmutable:    options.ReplaceOnImmutable,
+	}
+
+	return queueBuilder, queueOptions, nil
+}
+
 // generateObjects will cycle through all the generator of the applier and accumulate any object generated by them,
-// return false if an error is encountered
-func (a *Applier) generateObjects(objects []*unstructured.Unstructured, eventChannel chan event.Event, remoteGetter cache.RemoteResourceGetter) ([]*unstructured.Unstructured, bool) {
+// return an error if one of them fails
+func (a *Applier) generateObjects(objects []*unstructured.Unstructured, remoteGetter cache.RemoteResourceGetter) ([]*unstructured.Unstructured, error) {
 	var generatedObject []*unstructured.Unstructured
 	for _, rg := range a.generators {
 		for _, obj := range objects {
@@ -154,18 +233,17 @@ func (a *Applier) generateObjects(objects []*unstructured.Unstructured, eventCha
 
 			generated, err := rg.Generate(obj, remoteGetter)
 			if err != nil {
-				handleError(eventChannel, fmt.Errorf("generate resource failed: %w", err))
-				return generatedObject, true
+				return generatedObject, fmt.Errorf("generate resource failed: %w", err)
 			}
 			generatedObject = append(generatedObject, generated...)
 		}
 	}
 
-	return generatedObject, false
+	return generatedObject, nil
 }
 
-// mutateObjects will cycle through all the mutators of the applier, return false if an error is encountered
-func (a *Applier) mutateObjects(objects []*unstructured.Unstructured, eventChannel chan event.Event, remoteGetter cache.RemoteResourceGetter) bool {
+// mutateObjects will cycle through all the mutators of the applier, return an error if one of them fails
+func (a *Applier) mutateObjects(objects []*unstructured.Unstructured, remoteGetter cache.RemoteResourceGetter) error {
 	for _, mt := range a.mutators {
 		for _, obj := range objects {
 			objMetadata := meta.AsPartialObjectMetadata(obj)
@@ -179,20 +257,24 @@ func (a *Applier) mutateObjects(objects []*unstructured.Unstructured, eventChann
 			}
 
 			if err := mt.Mutate(obj, remoteGetter); err != nil {
-				handleError(eventChannel, fmt.Errorf("mutate resource failed: %w", err))
-				return true
+				return fmt.Errorf("mutate resource failed: %w", err)
 			}
 		}
 	}
 
-	return false
+	return nil
 }
 
 // loadObjectsFromInventory return the array of Unstructured objects that are being tracked in the inventory.
 // It will skip objects that are not found, and return an error only in case some other problem is encountered
 // during retrivial, like network problems, or missing permissions
 func (a *Applier) loadObjectsFromInventory(ctx context.Context, cache cache.RemoteResourceGetter) ([]*unstructured.Unstructured, error) {
-	objIDs, err := a.inventory.Load(ctx)
+	var objIDs sets.Set[resource.ObjectMetadata]
+	err := a.retryPolicy.Do(ctx, "inventory load", func() error {
+		var err error
+		objIDs, err = a.inventory.Load(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -232,10 +314,79 @@ func findObjectsToPrune(first []*unstructured.Unstructured, second []*unstructur
 	return returnedObjects
 }
 
-// handleError send a TypeError event in the channel with err payload
-func handleError(channel chan event.Event, err error) {
+// queueEvent create a TypeQueue event describing the steps of the queue and all the objects handled by them
+func queueEvent(steps []event.Step) event.Event {
+	objects := make([]*unstructured.Unstructured, 0)
+	foundObjects := sets.New[*unstructured.Unstructured]()
+	for _, step := range steps {
+		for _, obj := range step.Objects {
+			if foundObjects.Has(obj) {
+				continue
+			}
+			foundObjects.Insert(obj)
+			objects = append(objects, obj)
+		}
+	}
+
+	return event.Event{
+		Type:      event.TypeQueue,
+		Timestamp: time.Now(),
+		QueueInfo: event.QueueInfo{
+			Objects: objects,
+			Steps:   steps,
+		},
+	}
+}
+
+// invalidCRDWarnings return a TypeWarning event for every CRD in objects that is not valid
+func invalidCRDWarnings(objects []*unstructured.Unstructured) []event.Event {
+	var warnings []event.Event
+	for _, crd := range resource.FindCRDs(objects) {
+		if err := resource.ValidateCRD(crd); err != nil {
+			warnings = append(warnings, event.Event{
+				Type:      event.TypeWarning,
+				Timestamp: time.Now(),
+				WarningInfo: event.WarningInfo{
+					Object:  crd,
+					Message: "invalid CRD found: " + resource.WithSource(crd, err).Error(),
+				},
+			})
+		}
+	}
+
+	return warnings
+}
+
+// contextWithRetryEvents return a copy of ctx that will send a TypeRetry event in channel for every retry of
+// the requests made with it
+func contextWithRetryEvents(ctx context.Context, channel chan event.Event) context.Context {
+	return retry.ContextWithNotifyFunc(ctx, func(info event.RetryInfo) {
+		channel <- event.Event{
+			Type:      event.TypeRetry,
+			Timestamp: time.Now(),
+			RetryInfo: info,
+		}
+	})
+}
+
+// contextWithWarningEvents return a copy of ctx that will send a TypeWarning event in channel for every warning
+// returned by the api-server to the requests made outside the tasks, like the load of the inventory
+func contextWithWarningEvents(ctx context.Context, channel chan event.Event) context.Context {
+	return util.ContextWithWarningFunc(ctx, func(message string) {
+		channel <- event.Event{
+			Type:        event.TypeWarning,
+			Timestamp:   time.Now(),
+			WarningInfo: event.WarningInfo{Message: message},
+		}
+	})
+}
+
+// handleError send a TypeError event in the channel with err payload, and record it in the span of ctx
+func handleError(ctx context.Context, channel chan event.Event, err error) {
+	telemetry.RecordError(ctx, err)
 	channel <- event.Event{
-		Type: event.TypeError,
+		Type:      event.TypeError,
+		Timestamp: time.Now(),
 		ErrorInfo: event.ErrorInfo{
 			Error: err,
 		},
@@ -19,9 +19,12 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -33,6 +36,8 @@ import (
 	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
 	"github.com/mia-platform/jpl/pkg/mutator"
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/runner/task"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
 )
 
@@ -61,6 +66,8 @@ func TestApplierRun(t *testing.T) {
 
 	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
 	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	detachedNamespace := namespace.DeepCopy()
+	detachedNamespace.SetAnnotations(map[string]string{resource.LifecycleDeletionAnnotation: resource.PreventDeletion})
 
 	testCases := map[string]struct {
 		objects          []*unstructured.Unstructured
@@ -74,6 +81,15 @@ func TestApplierRun(t *testing.T) {
 				namespace,
 			},
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							namespace,
+							deployment,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -84,8 +100,9 @@ func TestApplierRun(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: namespace,
-						Status: event.StatusSuccessful,
+						Object:    namespace,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -98,8 +115,9 @@ func TestApplierRun(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -159,6 +177,15 @@ func TestApplierRun(t *testing.T) {
 				namespace,
 			},
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							namespace,
+							deployment,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -169,8 +196,9 @@ func TestApplierRun(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: namespace,
-						Status: event.StatusSuccessful,
+						Object:    namespace,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyUnchanged,
 					},
 				},
 				{
@@ -183,8 +211,9 @@ func TestApplierRun(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -243,6 +272,15 @@ func TestApplierRun(t *testing.T) {
 				namespace,
 			},
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+							namespace,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -253,8 +291,9 @@ func TestApplierRun(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -279,6 +318,91 @@ func TestApplierRun(t *testing.T) {
 						Status: event.StatusSuccessful,
 					},
 				},
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusSuccessful,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(namespace),
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+			statusEvents: []event.Event{
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Message:        "",
+						Status:         event.StatusSuccessful,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+			},
+		},
+		"Apply and skip prune of objects with deletion prevented": {
+			objects: []*unstructured.Unstructured{
+				deployment,
+			},
+			inventoryObjects: []*unstructured.Unstructured{
+				detachedNamespace,
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+							detachedNamespace,
+						},
+					},
+				},
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Object: deployment,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
+					},
+				},
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Message:        "",
+						Status:         event.StatusSuccessful,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: detachedNamespace,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: detachedNamespace,
+						Status: event.StatusSkipped,
+					},
+				},
 				{
 					Type: event.TypeInventory,
 					InventoryInfo: event.InventoryInfo{
@@ -352,6 +476,10 @@ func TestApplierRun(t *testing.T) {
 						break loop
 					}
 
+					// task lifecycle events are checked by the runner tests
+					if e.Type == event.TypeTask {
+						continue
+					}
 					events = append(events, e)
 				}
 			}
@@ -382,6 +510,16 @@ func TestGenerators(t *testing.T) {
 			},
 			generator: &fakeGenerator{resource: job},
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+							cronjonb,
+							job,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -392,8 +530,9 @@ func TestGenerators(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -406,8 +545,9 @@ func TestGenerators(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: cronjonb,
-						Status: event.StatusSuccessful,
+						Object:    cronjonb,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -420,8 +560,9 @@ func TestGenerators(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: job,
-						Status: event.StatusSuccessful,
+						Object:    job,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -475,6 +616,10 @@ func TestGenerators(t *testing.T) {
 						break loop
 					}
 
+					// task lifecycle events are checked by the runner tests
+					if e.Type == event.TypeTask {
+						continue
+					}
 					events = append(events, e)
 				}
 			}
@@ -502,6 +647,14 @@ func TestMutators(t *testing.T) {
 			},
 			mutator: mutator.NewLabelsMutator(map[string]string{"foo": "bar"}),
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -512,8 +665,9 @@ func TestMutators(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -536,6 +690,14 @@ func TestMutators(t *testing.T) {
 			},
 			mutator: mutator.NewLabelsMutator(nil),
 			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+						},
+					},
+				},
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
@@ -546,8 +708,9 @@ func TestMutators(t *testing.T) {
 				{
 					Type: event.TypeApply,
 					ApplyInfo: event.ApplyInfo{
-						Object: deployment,
-						Status: event.StatusSuccessful,
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyCreated,
 					},
 				},
 				{
@@ -600,6 +763,10 @@ func TestMutators(t *testing.T) {
 						break loop
 					}
 
+					// task lifecycle events are checked by the runner tests
+					if e.Type == event.TypeTask {
+						continue
+					}
 					events = append(events, e)
 				}
 			}
@@ -612,6 +779,177 @@ func TestMutators(t *testing.T) {
 	}
 }
 
+func TestApplierFailurePropagation(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	deployment.SetNamespace(namespace.GetName())
+	service := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "service.yaml"))
+	job := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "job.yaml"))
+	// the job is applied after the service in the same group of the namespace for testing the fail fast option
+	require.NoError(t, resource.SetObjectExplicitDependencies(job, []resource.ObjectMetadata{
+		resource.ObjectMetadataFromUnstructured(service),
+	}))
+
+	testCases := map[string]struct {
+		options        ApplierOptions
+		expectedStatus map[*unstructured.Unstructured]event.Status
+		expectedErrors map[*unstructured.Unstructured]error
+	}{
+		"skip dependents of failed objects": {
+			options: ApplierOptions{DryRun: true},
+			expectedStatus: map[*unstructured.Unstructured]event.Status{
+				namespace:  event.StatusFailed,
+				service:    event.StatusSuccessful,
+				deployment: event.StatusSkipped,
+				job:        event.StatusSuccessful,
+			},
+			expectedErrors: map[*unstructured.Unstructured]error{
+				deployment: resource.DependencyFailedError{Dependency: resource.ObjectMetadataFromUnstructured(namespace)},
+			},
+		},
+		"skip all next groups with fail fast": {
+			options: ApplierOptions{DryRun: true, FailFast: true},
+			expectedStatus: map[*unstructured.Unstructured]event.Status{
+				namespace:  event.StatusFailed,
+				service:    event.StatusSuccessful,
+				deployment: event.StatusSkipped,
+				job:        event.StatusSkipped,
+			},
+			expectedErrors: map[*unstructured.Unstructured]error{
+				deployment: task.ErrFailFast,
+				job:        task.ErrFailFast,
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			// the namespace is not handled by the fake server so its apply will fail
+			applier, err := NewBuilder().
+				WithFactory(factoryForTesting(t, []*unstructured.Unstructured{deployment, service, job}, nil)).
+				WithInventory(&fakeinventory.Inventory{}).
+				WithStatusPoller(&fakePollerBuilder{}).
+				Build()
+			require.NoError(t, err)
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			objects := []*unstructured.Unstructured{namespace, deployment, service, job}
+			eventCh := applier.Run(withTimeout, objects, testCase.options)
+
+			finalStatus := make(map[*unstructured.Unstructured]event.Status)
+			finalErrors := make(map[*unstructured.Unstructured]error)
+			for e := range eventCh {
+				if e.Type != event.TypeApply || e.ApplyInfo.Status == event.StatusPending {
+					continue
+				}
+				finalStatus[e.ApplyInfo.Object] = e.ApplyInfo.Status
+				finalErrors[e.ApplyInfo.Object] = e.ApplyInfo.Error
+			}
+
+			assert.Equal(t, testCase.expectedStatus, finalStatus)
+			for obj, expectedErr := range testCase.expectedErrors {
+				assert.Equal(t, expectedErr, finalErrors[obj])
+			}
+		})
+	}
+}
+
+func TestApplierFailFastPruneWait(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+
+	// the namespace is not handled by the fake server so its apply will fail, and the deletion of the deployment
+	// is never reported by the poller
+	inv := &fakeinventory.Inventory{InventoryObjects: []*unstructured.Unstructured{deployment}}
+	applier, err := NewBuilder().
+		WithFactory(factoryForTesting(t, nil, []*unstructured.Unstructured{deployment})).
+		WithInventory(inv).
+		WithStatusPoller(&fakePollerBuilder{pendingDeletions: true}).
+		Build()
+	require.NoError(t, err)
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	result := applier.Apply(withTimeout, []*unstructured.Unstructured{namespace}, ApplierOptions{FailFast: true})
+	require.NoError(t, withTimeout.Err(), "the run has waited for the deletion of objects that have not been pruned")
+
+	deploymentID := resource.ObjectMetadataFromUnstructured(deployment)
+	var pruneErr error
+	for _, objectResult := range result.Objects {
+		if objectResult.Object == deploymentID {
+			pruneErr = objectResult.Error
+		}
+	}
+	assert.ErrorIs(t, pruneErr, task.ErrFailFast)
+
+	// the deployment has not been removed from the remote server and must be still tracked by the inventory
+	inventoryIDs := make([]resource.ObjectMetadata, 0, inv.Objects.Len())
+	for obj := range inv.Objects {
+		inventoryIDs = append(inventoryIDs, resource.ObjectMetadataFromUnstructured(obj))
+	}
+	assert.Contains(t, inventoryIDs, deploymentID)
+}
+
+func TestApplierTimeoutSaveInventory(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+
+	var saveCtxErr error
+	saved := false
+	inventory := &fakeinventory.Inventory{
+		SaveFunc: func(ctx context.Context, _ bool) error {
+			saved = true
+			saveCtxErr = ctx.Err()
+			return nil
+		},
+	}
+
+	// the fake poller will never report the deployment as current so the wait will last until the timeout
+	applier, err := NewBuilder().
+		WithFactory(factoryForTesting(t, []*unstructured.Unstructured{deployment}, nil)).
+		WithInventory(inventory).
+		WithStatusPoller(&fakePollerBuilder{}).
+		Build()
+	require.NoError(t, err)
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	eventCh := applier.Run(withTimeout, []*unstructured.Unstructured{deployment}, ApplierOptions{Timeout: 200 * time.Millisecond})
+	var events []string
+	for e := range eventCh {
+		if e.Type == event.TypeStatusUpdate || e.Type == event.TypeTask {
+			continue
+		}
+		events = append(events, e.String())
+	}
+
+	require.NoError(t, withTimeout.Err())
+	assert.True(t, saved)
+	assert.NoError(t, saveCtxErr)
+	assert.Equal(t, []string{
+		"queue started for: [Deployment.apps nginx]",
+		"Deployment.apps nginx: apply started...",
+		"Deployment.apps nginx: created",
+		"inventory: apply started...",
+		"inventory: applied successfully",
+		context.DeadlineExceeded.Error(),
+	}, events)
+}
+
 func TestFilters(t *testing.T) {
 	testdataPath := "testdata"
 	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
@@ -623,6 +961,12 @@ func TestFilters(t *testing.T) {
 	}
 
 	expectedEvents := []event.Event{
+		{
+			Type: event.TypeQueue,
+			QueueInfo: event.QueueInfo{
+				Objects: objects,
+			},
+		},
 		{
 			Type: event.TypeApply,
 			ApplyInfo: event.ApplyInfo{
@@ -640,8 +984,9 @@ func TestFilters(t *testing.T) {
 		{
 			Type: event.TypeApply,
 			ApplyInfo: event.ApplyInfo{
-				Object: job,
-				Status: event.StatusSuccessful,
+				Object:    job,
+				Status:    event.StatusSuccessful,
+				Operation: event.ApplyCreated,
 			},
 		},
 		{
@@ -678,6 +1023,10 @@ loop:
 				break loop
 			}
 
+			// task lifecycle events are checked by the runner tests
+			if e.Type == event.TypeTask {
+				continue
+			}
 			events = append(events, e)
 		}
 	}
@@ -688,6 +1037,73 @@ loop:
 	}
 }
 
+func TestApplierPreview(t *testing.T) {
+	t.Parallel()
+	testdataPath := "testdata"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+
+	objects := []*unstructured.Unstructured{
+		deployment,
+	}
+	inventoryObjects := []*unstructured.Unstructured{
+		namespace,
+	}
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.TypeDiff,
+			DiffInfo: event.DiffInfo{
+				Object: deployment,
+				Action: event.DiffCreate,
+			},
+		},
+		{
+			Type: event.TypeDiff,
+			DiffInfo: event.DiffInfo{
+				Object: namespace,
+				Action: event.DiffPrune,
+			},
+		},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	applier := newTestApplier(t, objects, inventoryObjects, nil, nil, nil, nil)
+	eventCh := applier.Run(withTimeout, objects, ApplierOptions{Preview: true})
+	var events []event.Event
+loop:
+	for {
+		select {
+		case <-withTimeout.Done():
+			assert.Fail(t, "context endend in timeout, something is pending")
+			break loop
+
+		case e, open := <-eventCh:
+			if !open {
+				break loop
+			}
+
+			// task lifecycle events are checked by the runner tests
+			if e.Type == event.TypeTask {
+				continue
+			}
+			events = append(events, e)
+		}
+	}
+
+	require.Len(t, events, len(expectedEvents), "actual events found: %v", events)
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.Type, events[idx].Type)
+		assert.Equal(t, expectedEvent.DiffInfo.Object, events[idx].DiffInfo.Object)
+		assert.Equal(t, expectedEvent.DiffInfo.Action, events[idx].DiffInfo.Action)
+		assert.NotEmpty(t, events[idx].DiffInfo.Diff)
+		assert.NoError(t, events[idx].DiffInfo.Error)
+	}
+}
+
 func TestLoadObjectFromInventory(t *testing.T) {
 	testdataPath := "testdata"
 
@@ -758,3 +1174,93 @@ func (f *testFilter) Filter(obj *unstructured.Unstructured, _ cache.RemoteResour
 
 	return false, nil
 }
+
+func TestApplierTelemetry(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	objects := []*unstructured.Unstructured{deployment}
+	inventoryObjects := []*unstructured.Unstructured{namespace}
+
+	registry := prometheus.NewPedanticRegistry()
+	metrics, err := telemetry.NewMetrics(registry)
+	require.NoError(t, err)
+	tracerProvider := &telemetry.FakeTracerProvider{}
+
+	applier, err := NewBuilder().
+		WithFactory(factoryForTesting(t, objects, inventoryObjects)).
+		WithInventory(&fakeinventory.Inventory{InventoryObjects: inventoryObjects}).
+		WithStatusPoller(&fakePollerBuilder{}).
+		WithTracerProvider(tracerProvider).
+		WithMetrics(metrics).
+		Build()
+	require.NoError(t, err)
+
+	result := applier.Apply(t.Context(), objects, ApplierOptions{DisableWait: true})
+	require.NoError(t, result.Err())
+
+	type spanRelation struct {
+		name   string
+		parent string
+	}
+	relations := make([]spanRelation, 0)
+	for _, span := range tracerProvider.Spans() {
+		assert.True(t, span.Ended, "span %s not ended", span.Name)
+		relations = append(relations, spanRelation{name: span.Name, parent: span.Parent})
+	}
+
+	assert.Equal(t, []spanRelation{
+		{name: "apply"},
+		{name: "task apply", parent: "apply"},
+		{name: "apply object", parent: "task apply"},
+		{name: "task prune", parent: "apply"},
+		{name: "prune object", parent: "task prune"},
+		{name: "task inventory", parent: "apply"},
+	}, relations)
+
+	expectedMetrics := `
+		# HELP jpl_objects_total Number of objects handled by the runs, partitioned by operation and final status.
+		# TYPE jpl_objects_total counter
+		jpl_objects_total{operation="apply",status="successful"} 1
+		jpl_objects_total{operation="prune",status="successful"} 1
+	`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expectedMetrics), "jpl_objects_total"))
+	count, err := testutil.GatherAndCount(registry, "jpl_api_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestApplierDeletionPrevented(t *testing.T) {
+	t.Parallel()
+	testdataPath := "testdata"
+
+	detachedDeployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	detachedDeployment.SetAnnotations(map[string]string{resource.LifecycleDeletionAnnotation: resource.PreventDeletion})
+	keptNamespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	keptNamespace.SetAnnotations(map[string]string{resource.OnRemoveAnnotation: resource.OnRemoveKeep})
+	inventoryObjects := []*unstructured.Unstructured{detachedDeployment, keptNamespace}
+
+	inv := &fakeinventory.Inventory{InventoryObjects: inventoryObjects}
+	applier, err := NewBuilder().
+		WithFactory(factoryForTesting(t, nil, inventoryObjects)).
+		WithInventory(inv).
+		WithStatusPoller(&fakePollerBuilder{}).
+		Build()
+	require.NoError(t, err)
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	result := applier.Apply(withTimeout, nil, ApplierOptions{})
+	require.Empty(t, result.Errors)
+
+	require.Len(t, result.Objects, 2)
+	for _, objectResult := range result.Objects {
+		assert.Equal(t, OutcomeSkipped, objectResult.Outcome)
+	}
+
+	// the objects are left on the remote server and are not tracked anymore by the inventory
+	assert.Empty(t, inv.Objects)
+}
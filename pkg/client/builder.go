@@ -19,13 +19,18 @@ import (
 	"errors"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	"github.com/mia-platform/jpl/pkg/filter"
 	"github.com/mia-platform/jpl/pkg/generator"
 	"github.com/mia-platform/jpl/pkg/inventory"
 	"github.com/mia-platform/jpl/pkg/mutator"
 	"github.com/mia-platform/jpl/pkg/poller"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
 	"github.com/mia-platform/jpl/pkg/runner/task"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 	"github.com/mia-platform/jpl/pkg/util"
 )
 
@@ -39,6 +44,9 @@ type Builder struct {
 	filters             []filter.Interface
 	poller              poller.StatusPoller
 	customResourceCheck poller.CustomStatusCheckers
+	tracerProvider      trace.TracerProvider
+	metrics             *telemetry.Metrics
+	retryPolicy         *retry.Policy
 }
 
 // NewBuilder return a new Builder instance with configured defaults
@@ -89,6 +97,27 @@ func (b *Builder) WithCustomStatusChecker(customResourceCheck poller.CustomStatu
 	return b
 }
 
+// WithTracerProvider assign the TracerProvider used for tracing every run of the Applier with OpenTelemetry spans,
+// if not set the runs are not traced
+func (b *Builder) WithTracerProvider(tracerProvider trace.TracerProvider) *Builder {
+	b.tracerProvider = tracerProvider
+	return b
+}
+
+// WithMetrics assign the Prometheus metrics updated by every run of the Applier, if not set no metric is collected
+func (b *Builder) WithMetrics(metrics *telemetry.Metrics) *Builder {
+	b.metrics = metrics
+	return b
+}
+
+// WithRetryPolicy assign the policy used for retrying the requests to the api-server that fail for transient errors,
+// like throttling or temporary unavailability, every retry is reported with a TypeRetry event. If not set the
+// requests are never retried, retry.DefaultPolicy can be used as a starting point.
+func (b *Builder) WithRetryPolicy(policy *retry.Policy) *Builder {
+	b.retryPolicy = policy
+	return b
+}
+
 // Build use default values and configured builder porperty for correctly setup an Applier
 func (b *Builder) Build() (*Applier, error) {
 	if b.factory == nil {
@@ -123,6 +152,11 @@ func (b *Builder) Build() (*Applier, error) {
 		statusPoller = poller.NewDefaultStatusPoller(client, mapper, b.customResourceCheck)
 	}
 
+	tracerProvider := b.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+
 	return &Applier{
 		client:      client,
 		mapper:      mapper,
@@ -133,5 +167,9 @@ func (b *Builder) Build() (*Applier, error) {
 		mutators:    b.mutators,
 		filters:     b.filters,
 		poller:      statusPoller,
+
+		tracerProvider: tracerProvider,
+		metrics:        b.metrics,
+		retryPolicy:    b.retryPolicy,
 	}, nil
 }
@@ -27,39 +27,79 @@ import (
 	"k8s.io/client-go/dynamic"
 
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 )
 
+// resourceStatus contain the result of the request for a resource, done is closed when it is available
 type resourceStatus struct {
-	obj *unstructured.Unstructured
-	err error
+	done chan struct{}
+	obj  *unstructured.Unstructured
+	err  error
+	// stopped is true if the request has been interrupted by the context of its caller, and must be tried again
+	stopped bool
 }
 
 type CachedResourceGetter struct {
 	mapper meta.RESTMapper
 	client dynamic.Interface
 
+	retryPolicy *retry.Policy
+
+	// lock guard only the cache map, the requests are made without holding it so different resources can be
+	// retrieved concurrently
 	lock  sync.Mutex
-	cache map[resource.ObjectMetadata]resourceStatus
+	cache map[resource.ObjectMetadata]*resourceStatus
 }
 
 func NewCachedResourceGetter(mapper meta.RESTMapper, client dynamic.Interface) *CachedResourceGetter {
 	return &CachedResourceGetter{
 		mapper: mapper,
 		client: client,
-		cache:  make(map[resource.ObjectMetadata]resourceStatus),
+		cache:  make(map[resource.ObjectMetadata]*resourceStatus),
 	}
 }
 
+// WithRetryPolicy set the policy used for retrying the requests that have failed for transient errors
+func (rg *CachedResourceGetter) WithRetryPolicy(policy *retry.Policy) *CachedResourceGetter {
+	rg.retryPolicy = policy
+	return rg
+}
+
 // Get implement RemoteResourceGetter interface
 func (rg *CachedResourceGetter) Get(ctx context.Context, id resource.ObjectMetadata) (*unstructured.Unstructured, error) {
-	rg.lock.Lock()
-	defer rg.lock.Unlock()
-
-	if status, found := rg.cache[id]; found {
+	for {
+		rg.lock.Lock()
+		status, found := rg.cache[id]
+		if !found {
+			status = &resourceStatus{done: make(chan struct{})}
+			rg.cache[id] = status
+		}
+		rg.lock.Unlock()
+
+		// another caller is already retrieving the same resource, wait for its result
+		if found {
+			select {
+			case <-status.done:
+				if status.stopped {
+					continue
+				}
+				return status.obj, status.err
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		status.obj, status.err = rg.getObject(ctx, id)
+		// the errors caused by the context of this caller are not cached, the next callers will try again
+		if status.err != nil && ctx.Err() != nil {
+			status.stopped = true
+			rg.lock.Lock()
+			delete(rg.cache, id)
+			rg.lock.Unlock()
+		}
+		close(status.done)
 		return status.obj, status.err
 	}
-
-	return rg.getObject(ctx, id)
 }
 
 func (rg *CachedResourceGetter) getObject(ctx context.Context, id resource.ObjectMetadata) (*unstructured.Unstructured, error) {
@@ -68,17 +108,15 @@ func (rg *CachedResourceGetter) getObject(ctx context.Context, id resource.Objec
 		return nil, err
 	}
 
-	obj, err := rg.client.Resource(mapping.Resource).Namespace(id.Namespace).Get(ctx, id.Name, metav1.GetOptions{})
+	var obj *unstructured.Unstructured
+	err = rg.retryPolicy.Do(ctx, "get", func() error {
+		obj, err = rg.client.Resource(mapping.Resource).Namespace(id.Namespace).Get(ctx, id.Name, metav1.GetOptions{})
+		return err
+	})
 	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
-		rg.cache[id] = resourceStatus{}
 		return nil, nil
 	}
 
-	rg.cache[id] = resourceStatus{
-		obj: obj,
-		err: err,
-	}
-
 	return obj, err
 }
 
@@ -0,0 +1,62 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestCachedResourceGetterStoppedRequest(t *testing.T) {
+	t.Parallel()
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join("..", "..", "..", "testdata", "commons", "deployment.yaml"))
+	id := resource.ObjectMetadataFromUnstructured(deployment)
+
+	mapper, err := pkgtesting.NewTestClientFactory().ToRESTMapper()
+	require.NoError(t, err)
+	client := dynamicfake.NewSimpleDynamicClient(pkgtesting.Scheme, deployment)
+	var calls atomic.Int32
+	client.PrependReactor("get", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+		// the first request is interrupted by the context of its caller
+		if calls.Add(1) == 1 {
+			return true, nil, context.Canceled
+		}
+		return false, nil, nil
+	})
+	getter := NewCachedResourceGetter(mapper, client)
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err = getter.Get(canceled, id)
+	require.ErrorIs(t, err, context.Canceled)
+
+	obj, err := getter.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, deployment.GetName(), obj.GetName())
+	assert.Equal(t, int32(2), calls.Load())
+}
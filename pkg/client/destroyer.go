@@ -0,0 +1,95 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/mia-platform/jpl/pkg/client/cache"
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/inventory"
+)
+
+// DestroyerOptions options for the destroy step
+type DestroyerOptions struct {
+	DryRun      bool
+	DisableWait bool
+	Timeout     time.Duration
+}
+
+// Destroy will remove from the remote api-server all the objects tracked by the inventory in reverse dependency
+// order, and then the inventory itself if all the objects have been removed successfully, otherwise the inventory
+// is saved with the objects that are still present
+func (a *Applier) Destroy(ctx context.Context, options DestroyerOptions) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+
+	go func() {
+		defer close(eventChannel)
+
+		ctx, span := a.startSpan(ctx, "destroy", options.DryRun)
+		defer span.End()
+
+		destroyerCtx := ctx
+		if options.Timeout > 0 {
+			var cancel context.CancelFunc
+			destroyerCtx, cancel = context.WithTimeout(ctx, options.Timeout)
+			defer cancel()
+		}
+
+		resourceCache := cache.NewCachedResourceGetter(a.mapper, a.client).WithRetryPolicy(a.retryPolicy)
+		remoteObjects, err := a.loadObjectsFromInventory(contextWithWarningEvents(contextWithRetryEvents(destroyerCtx, eventChannel), eventChannel), resourceCache)
+		if err != nil {
+			handleError(destroyerCtx, eventChannel, err)
+			return
+		}
+
+		manager := inventory.NewManager(a.inventory, remoteObjects)
+		manager.RetryPolicy = a.retryPolicy
+		queueBuilder := QueueBuilder{
+			Client:       a.client,
+			Mapper:       a.mapper,
+			Manager:      manager,
+			RemoteGetter: resourceCache,
+			Poller:       a.poller,
+			Metrics:      a.metrics,
+			RetryPolicy:  a.retryPolicy,
+		}
+		queueOptions := QueueOptions{
+			DryRun:      options.DryRun,
+			Wait:        !options.DisableWait,
+			Prune:       true,
+			InventoryID: inventory.IDOf(a.inventory),
+		}
+
+		contextState := &RunnerState{
+			eventChannel: eventChannel,
+			manager:      manager,
+			context:      destroyerCtx,
+		}
+
+		tasks := queueBuilder.
+			WithPruneObjects(remoteObjects).
+			buildDestroyTasks(queueOptions)
+
+		eventChannel <- queueEvent(stepsFromTasks(tasks))
+		if err := a.runner.RunWithQueue(contextState, tasksQueue(tasks)); err != nil {
+			handleError(destroyerCtx, eventChannel, err)
+		}
+	}()
+
+	return eventChannel
+}
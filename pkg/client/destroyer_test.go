@@ -0,0 +1,359 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestApplierDestroy(t *testing.T) {
+	t.Parallel()
+	testdataPath := "testdata"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	foreignNamespace := namespace.DeepCopy()
+	inventory.SetOwner(foreignNamespace, "test/other")
+
+	testCases := map[string]struct {
+		inventory      *fakeinventory.Inventory
+		options        DestroyerOptions
+		expectedEvents []event.Event
+	}{
+		"destroy all objects tracked by the inventory": {
+			inventory: &fakeinventory.Inventory{
+				InventoryObjects: []*unstructured.Unstructured{
+					deployment,
+					namespace,
+				},
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							deployment,
+							namespace,
+						},
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: deployment,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: deployment,
+						Status: event.StatusSuccessful,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: namespace,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: namespace,
+						Status: event.StatusSuccessful,
+					},
+				},
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusSuccessful,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusSuccessful,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(namespace),
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+		},
+		"destroy in dry run will not wait": {
+			inventory: &fakeinventory.Inventory{
+				InventoryObjects: []*unstructured.Unstructured{
+					namespace,
+				},
+			},
+			options: DestroyerOptions{DryRun: true},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							namespace,
+						},
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: namespace,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: namespace,
+						Status: event.StatusSuccessful,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+		},
+		"objects owned by another inventory are not removed": {
+			inventory: &fakeinventory.Inventory{
+				InventoryID: "test/inventory",
+				InventoryObjects: []*unstructured.Unstructured{
+					foreignNamespace,
+				},
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeQueue,
+					QueueInfo: event.QueueInfo{
+						Objects: []*unstructured.Unstructured{
+							foreignNamespace,
+						},
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: foreignNamespace,
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Object: foreignNamespace,
+						Status: event.StatusSkipped,
+						Error:  &inventory.OwnershipError{Owner: "test/other", Inventory: "test/inventory"},
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+		},
+		"empty inventory only remove the inventory": {
+			inventory: &fakeinventory.Inventory{},
+			expectedEvents: []event.Event{
+				{Type: event.TypeQueue},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+		},
+		"error removing the inventory": {
+			inventory: &fakeinventory.Inventory{
+				DeleteErr: errors.New("error during delete"),
+			},
+			expectedEvents: []event.Event{
+				{Type: event.TypeQueue},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusFailed,
+						Error:  errors.New("error during delete"),
+					},
+				},
+			},
+		},
+		"error loading the inventory": {
+			inventory: &fakeinventory.Inventory{
+				LoadErr: errors.New("error during load"),
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeError,
+					ErrorInfo: event.ErrorInfo{
+						Error: errors.New("error during load"),
+					},
+				},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			applier, err := NewBuilder().
+				WithFactory(factoryForTesting(t, nil, testCase.inventory.InventoryObjects)).
+				WithInventory(testCase.inventory).
+				WithStatusPoller(&fakePollerBuilder{}).
+				Build()
+			require.NoError(t, err)
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			eventCh := applier.Destroy(withTimeout, testCase.options)
+			var events []event.Event
+
+		loop:
+			for {
+				select {
+				case <-withTimeout.Done():
+					assert.Fail(t, "context ended in timeout, something is pending")
+					break loop
+
+				case e, open := <-eventCh:
+					if !open {
+						break loop
+					}
+
+					// task lifecycle events are checked by the runner tests
+					if e.Type == event.TypeTask {
+						continue
+					}
+					events = append(events, e)
+				}
+			}
+
+			require.Len(t, events, len(testCase.expectedEvents), "actual events found: %v", events)
+			for idx, expectedEvent := range testCase.expectedEvents {
+				assert.Equal(t, expectedEvent.String(), events[idx].String())
+			}
+		})
+	}
+}
+
+func TestApplierPartialDestroy(t *testing.T) {
+	t.Parallel()
+	testdataPath := "testdata"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	inv := &fakeinventory.Inventory{InventoryObjects: []*unstructured.Unstructured{deployment, namespace}}
+
+	factory := factoryForTesting(t, nil, inv.InventoryObjects)
+	dynamicClient, err := factory.DynamicClient()
+	require.NoError(t, err)
+	dynamicClient.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, namespace.GetName(), errors.New("forbidden"))
+	})
+
+	applier, err := NewBuilder().
+		WithFactory(factory).
+		WithInventory(inv).
+		WithStatusPoller(&fakePollerBuilder{}).
+		Build()
+	require.NoError(t, err)
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	var warnings []string
+	var inventoryEvent event.Event
+	for e := range applier.Destroy(withTimeout, DestroyerOptions{}) {
+		switch e.Type {
+		case event.TypeWarning:
+			warnings = append(warnings, e.WarningInfo.Message)
+		case event.TypeInventory:
+			inventoryEvent = e
+		}
+	}
+	require.NoError(t, withTimeout.Err())
+
+	assert.Equal(t, []string{"inventory has not been deleted because some of its objects have not been removed"}, warnings)
+	assert.Equal(t, event.StatusSuccessful, inventoryEvent.InventoryInfo.Status)
+
+	// only the namespace that has failed to be deleted is still tracked by the inventory
+	require.Len(t, inv.Objects, 1)
+	assert.Equal(t, resource.ObjectMetadataFromUnstructured(namespace), resource.ObjectMetadataFromUnstructured(inv.Objects.UnsortedList()[0]))
+}
@@ -28,7 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/cli-runtime/pkg/resource"
+	cliresource "k8s.io/cli-runtime/pkg/resource"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/rest/fake"
 
@@ -39,6 +39,7 @@ import (
 	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
 	"github.com/mia-platform/jpl/pkg/mutator"
 	"github.com/mia-platform/jpl/pkg/poller"
+	"github.com/mia-platform/jpl/pkg/resource"
 	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
 	"github.com/mia-platform/jpl/pkg/util"
 )
@@ -93,7 +94,7 @@ func fakeRESTClient(t *testing.T, handler *handler) *fake.RESTClient {
 	t.Helper()
 
 	return &fake.RESTClient{
-		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		NegotiatedSerializer: cliresource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
 		Client: fake.CreateHTTPClient(func(request *http.Request) (*http.Response, error) {
 			t.Logf("Received %s call for %s", request.Method, request.URL)
 			if handled, response, err := handler.handleRequest(t, request); handled {
@@ -182,6 +183,8 @@ var _ poller.StatusPoller = &fakePollerBuilder{}
 
 type fakePollerBuilder struct {
 	events []event.Event
+	// pendingDeletions if set the deletion of the objects is never reported
+	pendingDeletions bool
 }
 
 func (b *fakePollerBuilder) Start(ctx context.Context, _ []*unstructured.Unstructured) <-chan event.Event {
@@ -199,3 +202,28 @@ func (b *fakePollerBuilder) Start(ctx context.Context, _ []*unstructured.Unstruc
 
 	return eventCh
 }
+
+func (b *fakePollerBuilder) StartDeletion(ctx context.Context, objs []*unstructured.Unstructured) <-chan event.Event {
+	eventCh := make(chan event.Event)
+
+	go func() {
+		defer close(eventCh)
+
+		for _, obj := range objs {
+			if b.pendingDeletions {
+				break
+			}
+			eventCh <- event.Event{
+				Type: event.TypeStatusUpdate,
+				StatusUpdateInfo: event.StatusUpdateInfo{
+					Status:         event.StatusSuccessful,
+					ObjectMetadata: resource.ObjectMetadataFromUnstructured(obj),
+				},
+			}
+		}
+
+		<-ctx.Done()
+	}()
+
+	return eventCh
+}
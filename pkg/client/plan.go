@@ -0,0 +1,172 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/runner"
+)
+
+// Action determine what will happen to an object when a Plan is executed.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=Action -trimprefix=Action
+type Action int
+
+const (
+	ActionCreate Action = iota
+	ActionUpdate
+	ActionUnchanged
+	ActionSkip
+	ActionPrune
+	// ActionFail is used for objects that the server rejected during the dry run, the reason is in their Error
+	ActionFail
+)
+
+// Plan describe what the Applier will do when running with a set of objects
+type Plan struct {
+	// Steps contains the steps that will be executed in order, with the objects handled by each of them
+	Steps []event.Step
+	// Objects contains the action planned for every object to apply or prune, in the order they will be handled
+	Objects []PlannedObject
+}
+
+// PlannedObject contains the action that will be made on an object
+type PlannedObject struct {
+	Object *unstructured.Unstructured
+	Action Action
+	// Diff contains the changes to the remote object in unified format
+	Diff string
+	// Error is set if the action for the object cannot be computed, or if the object will fail to be applied
+	Error error
+}
+
+// Plan return what the Applier will do when running with the passed objects and options. The inventory is loaded
+// and the objects go through generators, mutators, filters and dependency sorting like in Run, the actions are
+// computed using server side dry runs so the remote objects are never modified
+func (a *Applier) Plan(ctx context.Context, objects []*unstructured.Unstructured, options ApplierOptions) (*Plan, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	queueBuilder, queueOptions, err := a.prepareQueue(ctx, objects, options)
+	if err != nil {
+		return nil, err
+	}
+
+	queueOptions.Preview = false
+	tasks, err := queueBuilder.buildTasks(queueOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	queueOptions.Preview = true
+	previewTasks, err := queueBuilder.buildTasks(queueOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &planState{
+		context: ctx,
+		diffs:   make(map[*unstructured.Unstructured]event.DiffInfo),
+	}
+	for _, previewTask := range previewTasks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		previewTask.Run(state)
+	}
+
+	plan := &Plan{Steps: stepsFromTasks(tasks)}
+	for _, step := range plan.Steps {
+		if step.Type != event.StepApply && step.Type != event.StepPrune {
+			continue
+		}
+
+		for _, obj := range step.Objects {
+			plan.Objects = append(plan.Objects, plannedObject(obj, state.diffs))
+		}
+	}
+
+	return plan, nil
+}
+
+// plannedObject return the action for obj using the diffs computed for it, objects without a diff or with a
+// skipped one will be left untouched
+func plannedObject(obj *unstructured.Unstructured, diffs map[*unstructured.Unstructured]event.DiffInfo) PlannedObject {
+	planned := PlannedObject{
+		Object: obj,
+		Action: ActionSkip,
+	}
+
+	diff, found := diffs[obj]
+	if !found {
+		return planned
+	}
+
+	planned.Diff = diff.Diff
+	planned.Error = diff.Error
+	switch diff.Action {
+	case event.DiffCreate:
+		planned.Action = ActionCreate
+	case event.DiffUpdate:
+		planned.Action = ActionUpdate
+	case event.DiffPrune:
+		planned.Action = ActionPrune
+	case event.DiffUnchanged:
+		planned.Action = ActionUnchanged
+	case event.DiffFailed:
+		planned.Action = ActionFail
+	}
+
+	return planned
+}
+
+var _ runner.State = &planState{}
+
+// planState collect the diffs computed by the preview tasks for building a Plan
+type planState struct {
+	context context.Context
+	diffs   map[*unstructured.Unstructured]event.DiffInfo
+}
+
+func (s *planState) GetContext() context.Context {
+	return s.context
+}
+
+func (s *planState) SendEvent(e event.Event) {
+	if e.Type == event.TypeDiff {
+		s.diffs[e.DiffInfo.Object] = e.DiffInfo
+	}
+}
+
+func (s *planState) SkipWaitCurrentStatus(*unstructured.Unstructured) bool {
+	return false
+}
+
+func (s *planState) IsFailed(resource.ObjectMetadata) bool {
+	return false
+}
+
+func (s *planState) HasFailures() bool {
+	return false
+}
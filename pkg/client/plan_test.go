@@ -0,0 +1,111 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/filter"
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestApplierPlan(t *testing.T) {
+	t.Parallel()
+	testdataPath := "testdata"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	job := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "job.yaml"))
+
+	testCases := map[string]struct {
+		objects          []*unstructured.Unstructured
+		inventoryObjects []*unstructured.Unstructured
+		filter           filter.Interface
+		options          ApplierOptions
+		expectedSteps    []event.StepType
+		expectedActions  map[resource.ObjectMetadata]Action
+	}{
+		"apply and prune objects": {
+			objects:          []*unstructured.Unstructured{deployment},
+			inventoryObjects: []*unstructured.Unstructured{namespace},
+			expectedSteps: []event.StepType{
+				event.StepApply,
+				event.StepWait,
+				event.StepPrune,
+				event.StepWaitDeletion,
+				event.StepInventory,
+			},
+			expectedActions: map[resource.ObjectMetadata]Action{
+				resource.ObjectMetadataFromUnstructured(deployment): ActionCreate,
+				resource.ObjectMetadataFromUnstructured(namespace):  ActionPrune,
+			},
+		},
+		"dry run will not wait": {
+			objects: []*unstructured.Unstructured{deployment},
+			options: ApplierOptions{DryRun: true},
+			expectedSteps: []event.StepType{
+				event.StepApply,
+				event.StepInventory,
+			},
+			expectedActions: map[resource.ObjectMetadata]Action{
+				resource.ObjectMetadataFromUnstructured(deployment): ActionCreate,
+			},
+		},
+		"filtered objects are skipped": {
+			objects: []*unstructured.Unstructured{deployment, job},
+			filter:  &testFilter{},
+			options: ApplierOptions{DisableWait: true},
+			expectedSteps: []event.StepType{
+				event.StepApply,
+				event.StepInventory,
+			},
+			expectedActions: map[resource.ObjectMetadata]Action{
+				resource.ObjectMetadataFromUnstructured(deployment): ActionSkip,
+				resource.ObjectMetadataFromUnstructured(job):        ActionCreate,
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			applier := newTestApplier(t, testCase.objects, testCase.inventoryObjects, nil, nil, nil, testCase.filter)
+			plan, err := applier.Plan(t.Context(), testCase.objects, testCase.options)
+			require.NoError(t, err)
+
+			steps := make([]event.StepType, 0, len(plan.Steps))
+			for _, step := range plan.Steps {
+				steps = append(steps, step.Type)
+			}
+			assert.Equal(t, testCase.expectedSteps, steps)
+
+			actions := make(map[resource.ObjectMetadata]Action, len(plan.Objects))
+			for _, planned := range plan.Objects {
+				assert.NoError(t, planned.Error)
+				actions[resource.ObjectMetadataFromUnstructured(planned.Object)] = planned.Action
+			}
+			assert.Equal(t, testCase.expectedActions, actions)
+		})
+	}
+}
@@ -16,6 +16,7 @@
 package client
 
 import (
+	"slices"
 	"sort"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -23,19 +24,30 @@ import (
 	"k8s.io/client-go/dynamic"
 
 	"github.com/mia-platform/jpl/pkg/client/cache"
+	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/filter"
 	"github.com/mia-platform/jpl/pkg/inventory"
 	"github.com/mia-platform/jpl/pkg/poller"
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
 	"github.com/mia-platform/jpl/pkg/runner/task"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 )
 
 type QueueOptions struct {
-	Wait         bool
-	DryRun       bool
-	Prune        bool
-	FieldManager string
+	Wait           bool
+	DryRun         bool
+	Prune          bool
+	Preview        bool
+	FieldManager   string
+	Concurrency    int
+	InventoryID    string
+	AdoptionPolicy inventory.AdoptionPolicy
+	FailFast       bool
+
+	DisableForceConflicts bool
+	ReplaceOnImmutable    bool
 }
 
 type QueueBuilder struct {
@@ -49,6 +61,8 @@ type QueueBuilder struct {
 	InfoFetcher  task.InfoFetcher
 	RemoteGetter cache.RemoteResourceGetter
 	Poller       poller.StatusPoller
+	Metrics      *telemetry.Metrics
+	RetryPolicy  *retry.Policy
 }
 
 func (b *QueueBuilder) WithObjects(objs []*unstructured.Unstructured) *QueueBuilder {
@@ -62,61 +76,208 @@ func (b *QueueBuilder) WithPruneObjects(objs []*unstructured.Unstructured) *Queu
 }
 
 func (b *QueueBuilder) Build(options QueueOptions) (<-chan runner.Task, error) {
-	tasks := make([]runner.Task, 0)
+	tasks, err := b.buildTasks(options)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(b.objects) > 0 {
-		graph, err := resource.NewDependencyGraph(b.objects)
-		if err != nil {
-			return nil, err
-		}
+	return tasksQueue(tasks), nil
+}
 
-		groups, err := graph.SortedResourceGroups()
-		if err != nil {
-			return nil, err
-		}
+// buildTasks return the ordered list of tasks that will be put in the queue
+func (b *QueueBuilder) buildTasks(options QueueOptions) ([]runner.Task, error) {
+	if options.Preview {
+		return b.buildPreview(options)
+	}
 
-		for _, group := range groups {
-			tasks = append(tasks, &task.ApplyTask{
-				DryRun:       options.DryRun,
-				FieldManager: options.FieldManager,
+	tasks := make([]runner.Task, 0)
+	groups, graph, err := b.applyGroups()
+	if err != nil {
+		return nil, err
+	}
 
-				Objects:      group,
-				Filters:      b.Filters,
-				InfoFetcher:  b.InfoFetcher,
-				RemoteGetter: b.RemoteGetter,
+	for _, group := range groups {
+		tasks = append(tasks, &task.ApplyTask{
+			DryRun:         options.DryRun,
+			FieldManager:   options.FieldManager,
+			Concurrency:    options.Concurrency,
+			InventoryID:    options.InventoryID,
+			AdoptionPolicy: options.AdoptionPolicy,
+			FailFast:       options.FailFast,
+
+			DisableForceConflicts: options.DisableForceConflicts,
+			ReplaceOnImmutable:    options.ReplaceOnImmutable,
+
+			Graph:        graph,
+			Poller:       b.Poller,
+			Metrics:      b.Metrics,
+			RetryPolicy:  b.RetryPolicy,
+			Objects:      group,
+			Filters:      b.Filters,
+			InfoFetcher:  b.InfoFetcher,
+			RemoteGetter: b.RemoteGetter,
+		})
+		if !options.DryRun && options.Wait {
+			tasks = append(tasks, &task.WaitTask{
+				Objects: group,
+				Poller:  b.Poller,
+				Mapper:  b.Mapper,
+				Metrics: b.Metrics,
 			})
-			if !options.DryRun && options.Wait {
-				tasks = append(tasks, &task.WaitTask{
-					Objects: group,
-					Poller:  b.Poller,
-					Mapper:  b.Mapper,
-				})
-			}
 		}
 	}
 
+	if options.Prune {
+		tasks = append(tasks, b.pruneTasks(options)...)
+	}
+
+	tasks = append(tasks, &task.InventoryTask{
+		Manager: b.Manager,
+		DryRun:  options.DryRun,
+	})
+
+	return tasks, nil
+}
+
+// buildPreview return the tasks that will only compute the changes that will be made to the remote server
+// by the objects and prune objects, without modifying it
+func (b *QueueBuilder) buildPreview(options QueueOptions) ([]runner.Task, error) {
+	tasks := make([]runner.Task, 0)
+	groups, _, err := b.applyGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		tasks = append(tasks, &task.DiffTask{
+			FieldManager:   options.FieldManager,
+			InventoryID:    options.InventoryID,
+			AdoptionPolicy: options.AdoptionPolicy,
+
+			DisableForceConflicts: options.DisableForceConflicts,
+
+			Objects:      group,
+			Filters:      b.Filters,
+			InfoFetcher:  b.InfoFetcher,
+			RemoteGetter: b.RemoteGetter,
+		})
+	}
+
 	if options.Prune && len(b.pruneObjects) > 0 {
 		sort.Sort(sort.Reverse(resource.SortableObjects(b.pruneObjects)))
+		tasks = append(tasks, &task.DiffTask{
+			Prune:       true,
+			InventoryID: options.InventoryID,
+			Objects:     b.pruneObjects,
+		})
+	}
+
+	return tasks, nil
+}
+
+// applyGroups return the objects divided in groups sorted by their dependencies, and the graph used for sorting them
+func (b *QueueBuilder) applyGroups() ([][]*unstructured.Unstructured, *resource.DependencyGraph, error) {
+	if len(b.objects) == 0 {
+		return nil, nil, nil
+	}
+
+	graph, err := resource.NewDependencyGraph(b.objects)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups, err := graph.SortedResourceGroups()
+	return groups, graph, err
+}
+
+// BuildDestroy return a queue of tasks that will remove all the prune objects from the remote server in reverse
+// dependency order, and then the inventory itself
+func (b *QueueBuilder) BuildDestroy(options QueueOptions) (<-chan runner.Task, error) {
+	return tasksQueue(b.buildDestroyTasks(options)), nil
+}
+
+// buildDestroyTasks return the ordered list of tasks for removing the prune objects and then the inventory
+func (b *QueueBuilder) buildDestroyTasks(options QueueOptions) []runner.Task {
+	return append(b.pruneTasks(options), &task.InventoryTask{
+		Manager: b.Manager,
+		DryRun:  options.DryRun,
+		Delete:  true,
+	})
+}
+
+// pruneTasks return the tasks for deleting the prune objects group by group in the reverse order that is used for
+// applying them, waiting for each group to be removed before moving to the next one
+func (b *QueueBuilder) pruneTasks(options QueueOptions) []runner.Task {
+	tasks := make([]runner.Task, 0)
+	if len(b.pruneObjects) == 0 {
+		return tasks
+	}
+
+	// the objects to prune come from the remote server and can depend on objects that are still applied, or have
+	// outdated annotations, so the ordering is best effort and a cycle will prune all of them at once
+	groups, err := resource.NewLenientDependencyGraph(b.pruneObjects).SortedResourceGroups()
+	if err != nil {
+		groups = [][]*unstructured.Unstructured{b.pruneObjects}
+	}
+
+	for _, group := range slices.Backward(groups) {
+		sort.Sort(sort.Reverse(resource.SortableObjects(group)))
 		tasks = append(tasks, &task.PruneTask{
 			DryRun:       options.DryRun,
 			FieldManager: options.FieldManager,
+			InventoryID:  options.InventoryID,
+			FailFast:     options.FailFast,
 
-			Objects: b.pruneObjects,
-			Client:  b.Client,
-			Mapper:  b.Mapper,
+			Objects:     group,
+			Client:      b.Client,
+			Mapper:      b.Mapper,
+			Metrics:     b.Metrics,
+			RetryPolicy: b.RetryPolicy,
 		})
+		if _, canWaitDeletion := b.Poller.(poller.DeletionPoller); canWaitDeletion && !options.DryRun && options.Wait {
+			tasks = append(tasks, &task.WaitTask{
+				Objects:  group,
+				Poller:   b.Poller,
+				Mapper:   b.Mapper,
+				Metrics:  b.Metrics,
+				Deletion: true,
+			})
+		}
 	}
 
-	tasks = append(tasks, &task.InventoryTask{
-		Manager: b.Manager,
-		DryRun:  options.DryRun,
-	})
+	return tasks
+}
+
+// stepsFromTasks return the steps executed by tasks, in the same order, for describing the queue to the user
+func stepsFromTasks(tasks []runner.Task) []event.Step {
+	steps := make([]event.Step, 0, len(tasks))
+	for _, currentTask := range tasks {
+		switch t := currentTask.(type) {
+		case *task.ApplyTask:
+			steps = append(steps, event.Step{Type: event.StepApply, Objects: t.Objects})
+		case *task.WaitTask:
+			stepType := event.StepWait
+			if t.Deletion {
+				stepType = event.StepWaitDeletion
+			}
+			steps = append(steps, event.Step{Type: stepType, Objects: t.Objects})
+		case *task.PruneTask:
+			steps = append(steps, event.Step{Type: event.StepPrune, Objects: t.Objects})
+		case *task.InventoryTask:
+			steps = append(steps, event.Step{Type: event.StepInventory})
+		}
+	}
+
+	return steps
+}
 
+// tasksQueue return a closed channel already filled with tasks
+func tasksQueue(tasks []runner.Task) <-chan runner.Task {
 	queue := make(chan runner.Task, len(tasks))
 	for _, task := range tasks {
 		queue <- task
 	}
 
 	defer close(queue)
-	return queue, nil
+	return queue
 }
@@ -0,0 +1,174 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/inventory"
+	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
+	"github.com/mia-platform/jpl/pkg/poller"
+	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/runner"
+	"github.com/mia-platform/jpl/pkg/runner/task"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestBuildStagedPrune(t *testing.T) {
+	t.Parallel()
+
+	testdata := filepath.Join("..", "..", "testdata", "commons")
+	crd := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "cluster-crd.yaml"))
+	cr := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "cluster-cr.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespace.yaml"))
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployment.yaml"))
+	deployment.SetNamespace(namespace.GetName())
+	objects := []*unstructured.Unstructured{crd, namespace, cr, deployment}
+
+	externalDependency := deployment.DeepCopy()
+	require.NoError(t, resource.SetObjectExplicitDependencies(externalDependency, []resource.ObjectMetadata{
+		{Kind: "ConfigMap", Namespace: "default", Name: "still-applied"},
+	}))
+
+	cyclicDeployment := deployment.DeepCopy()
+	cyclicCR := cr.DeepCopy()
+	require.NoError(t, resource.SetObjectExplicitDependencies(cyclicDeployment, []resource.ObjectMetadata{resource.ObjectMetadataFromUnstructured(cr)}))
+	require.NoError(t, resource.SetObjectExplicitDependencies(cyclicCR, []resource.ObjectMetadata{resource.ObjectMetadataFromUnstructured(deployment)}))
+
+	testCases := map[string]struct {
+		pruneObjects  []*unstructured.Unstructured
+		poller        poller.StatusPoller
+		options       QueueOptions
+		expectedTasks []runner.Task
+	}{
+		"prune groups wait for deletion": {
+			pruneObjects: objects,
+			poller:       &poller.FakePoller{},
+			options:      QueueOptions{Prune: true, Wait: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{deployment, cr}},
+				&task.WaitTask{Objects: []*unstructured.Unstructured{deployment, cr}, Deletion: true},
+				&task.PruneTask{Objects: []*unstructured.Unstructured{namespace, crd}},
+				&task.WaitTask{Objects: []*unstructured.Unstructured{namespace, crd}, Deletion: true},
+				&task.InventoryTask{},
+			},
+		},
+		"prune groups without wait": {
+			pruneObjects: objects,
+			options:      QueueOptions{Prune: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{deployment, cr}},
+				&task.PruneTask{Objects: []*unstructured.Unstructured{namespace, crd}},
+				&task.InventoryTask{},
+			},
+		},
+		"prune groups in dry run": {
+			pruneObjects: objects,
+			poller:       &poller.FakePoller{},
+			options:      QueueOptions{Prune: true, Wait: true, DryRun: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{deployment, cr}, DryRun: true},
+				&task.PruneTask{Objects: []*unstructured.Unstructured{namespace, crd}, DryRun: true},
+				&task.InventoryTask{DryRun: true},
+			},
+		},
+		"no prune": {
+			pruneObjects: objects,
+			poller:       &poller.FakePoller{},
+			options:      QueueOptions{Wait: true},
+			expectedTasks: []runner.Task{
+				&task.InventoryTask{},
+			},
+		},
+		"poller without deletion support will not wait": {
+			pruneObjects: objects,
+			poller:       &statusOnlyPoller{StatusPoller: &poller.FakePoller{}},
+			options:      QueueOptions{Prune: true, Wait: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{deployment, cr}},
+				&task.PruneTask{Objects: []*unstructured.Unstructured{namespace, crd}},
+				&task.InventoryTask{},
+			},
+		},
+		"dependencies outside of the prune objects are ignored": {
+			pruneObjects: []*unstructured.Unstructured{namespace, externalDependency},
+			options:      QueueOptions{Prune: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{externalDependency}},
+				&task.PruneTask{Objects: []*unstructured.Unstructured{namespace}},
+				&task.InventoryTask{},
+			},
+		},
+		"cyclic dependencies are pruned together": {
+			pruneObjects: []*unstructured.Unstructured{cyclicDeployment, cyclicCR},
+			options:      QueueOptions{Prune: true},
+			expectedTasks: []runner.Task{
+				&task.PruneTask{Objects: []*unstructured.Unstructured{cyclicDeployment, cyclicCR}},
+				&task.InventoryTask{},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &QueueBuilder{
+				Manager: inventory.NewManager(&fakeinventory.Inventory{}, nil),
+				Poller:  testCase.poller,
+			}
+			queue, err := builder.
+				WithPruneObjects(testCase.pruneObjects).
+				Build(testCase.options)
+			require.NoError(t, err)
+
+			var tasks []runner.Task
+			for task := range queue {
+				tasks = append(tasks, task)
+			}
+
+			require.Len(t, tasks, len(testCase.expectedTasks))
+			for idx, expectedTask := range testCase.expectedTasks {
+				switch expected := expectedTask.(type) {
+				case *task.PruneTask:
+					actual, ok := tasks[idx].(*task.PruneTask)
+					require.True(t, ok, "task %d is %T", idx, tasks[idx])
+					assert.Equal(t, expected.DryRun, actual.DryRun)
+					assert.ElementsMatch(t, expected.Objects, actual.Objects)
+				case *task.WaitTask:
+					actual, ok := tasks[idx].(*task.WaitTask)
+					require.True(t, ok, "task %d is %T", idx, tasks[idx])
+					assert.Equal(t, expected.Deletion, actual.Deletion)
+					assert.ElementsMatch(t, expected.Objects, actual.Objects)
+				case *task.InventoryTask:
+					actual, ok := tasks[idx].(*task.InventoryTask)
+					require.True(t, ok, "task %d is %T", idx, tasks[idx])
+					assert.Equal(t, expected.DryRun, actual.DryRun)
+				}
+			}
+		})
+	}
+}
+
+// statusOnlyPoller is a StatusPoller that does not implement the poller.DeletionPoller interface
+type statusOnlyPoller struct {
+	poller.StatusPoller
+}
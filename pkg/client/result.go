@@ -0,0 +1,318 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+// Outcome determine the final result of an object at the end of a run.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=Outcome -trimprefix=Outcome
+type Outcome int
+
+const (
+	OutcomeApplied Outcome = iota
+	OutcomeFailed
+	OutcomeSkipped
+	OutcomePruned
+	OutcomePruneFailed
+	OutcomeCurrent
+	OutcomeTimedOut
+	// OutcomePlanned is used for objects whose changes have been computed by a preview run without applying them
+	OutcomePlanned
+)
+
+// RunResult contains the aggregated result of all the events of a run
+type RunResult struct {
+	// Objects contains the final result of every object, in the order they have been handled for the first time
+	Objects []ObjectResult
+	// Errors contains the errors of all the events that have reported one
+	Errors []error
+	// Warnings contains all the warning events of the run
+	Warnings []event.WarningInfo
+	// Duration is the time passed from the start to the end of the run
+	Duration time.Duration
+}
+
+// ObjectResult contains the final result of an object
+type ObjectResult struct {
+	Object  resource.ObjectMetadata
+	Outcome Outcome
+	// Action is the type of the events that have determined the outcome: TypeApply, TypeReplace, TypePrune or
+	// TypeDiff for the objects of a preview run
+	Action event.Type
+	// DiffAction is the change computed for the object when Action is TypeDiff
+	DiffAction event.DiffAction
+	// Error is the error that has caused the failure or skip of the object, if any
+	Error error
+	// Reason classify the failure of the object, it is event.ReasonNone if the object has not failed
+	Reason event.Reason
+	// Operation is what the api-server has done for applying the object, if known
+	Operation event.ApplyOperation
+	// Message is the last status message received for the object
+	Message string
+	// Duration is the time passed from the first to the last event received for the object
+	Duration time.Duration
+
+	start time.Time
+}
+
+// Err return all the errors of the run joined in a single error, or nil if the run has ended successfully
+func (r *RunResult) Err() error {
+	return errors.Join(r.Errors...)
+}
+
+// Succeeded return true if the run has not reported any error
+func (r *RunResult) Succeeded() bool {
+	return len(r.Errors) == 0
+}
+
+// Apply will apply the passed objects like Run, but it will block until the end of the run and return its result
+func (a *Applier) Apply(ctx context.Context, objects []*unstructured.Unstructured, options ApplierOptions) *RunResult {
+	return CollectResult(a.Run(ctx, objects, options))
+}
+
+// CollectResult consume all the events of a run from ch and return their aggregated result, see ResultCollector
+func CollectResult(ch <-chan event.Event) *RunResult {
+	collector := NewResultCollector()
+	for e := range ch {
+		collector.Add(e)
+	}
+
+	return collector.Result()
+}
+
+// ResultCollector keep track of the progress of a run for building its RunResult. The events are considered
+// failures following the IsErrorEvent method of the event, and objects that have been applied or pruned but have
+// not reached their current status or have not been removed before the run has been stopped are marked as
+// timed out.
+type ResultCollector struct {
+	result       *RunResult
+	objectsIndex map[resource.ObjectMetadata]int
+	// queueObjects contains all the objects that the run will handle
+	queueObjects []resource.ObjectMetadata
+	// waitObjects contains the objects that the run will wait to reach their current status
+	waitObjects sets.Set[resource.ObjectMetadata]
+	// waitDeletionObjects contains the objects that the run will wait to be removed
+	waitDeletionObjects sets.Set[resource.ObjectMetadata]
+	// removedObjects contains the pruned objects that have been removed from the remote server
+	removedObjects sets.Set[resource.ObjectMetadata]
+	stopped        bool
+	start          time.Time
+	now            func() time.Time
+}
+
+// NewResultCollector return a ResultCollector for a run that is starting now
+func NewResultCollector() *ResultCollector {
+	return &ResultCollector{
+		result:              &RunResult{},
+		objectsIndex:        make(map[resource.ObjectMetadata]int),
+		waitObjects:         sets.New[resource.ObjectMetadata](),
+		waitDeletionObjects: sets.New[resource.ObjectMetadata](),
+		removedObjects:      sets.New[resource.ObjectMetadata](),
+		start:               time.Now(),
+		now:                 time.Now,
+	}
+}
+
+// Result return the result of the run with all the events added until now, it must be called after the last
+// event of the run for correctly marking the timed out objects
+func (c *ResultCollector) Result() *RunResult {
+	c.finalize()
+	c.result.Duration = c.now().Sub(c.start)
+	return c.result
+}
+
+// Add update the result with the content of e
+//
+//gocyclo:ignore
+func (c *ResultCollector) Add(e event.Event) {
+	if e.IsErrorEvent() {
+		c.result.Errors = append(c.result.Errors, eventError(e))
+	}
+
+	switch e.Type {
+	case event.TypeWarning:
+		c.result.Warnings = append(c.result.Warnings, e.WarningInfo)
+	case event.TypeError:
+		if errors.Is(e.ErrorInfo.Error, context.DeadlineExceeded) || errors.Is(e.ErrorInfo.Error, context.Canceled) {
+			c.stopped = true
+		}
+	case event.TypeQueue:
+		for _, obj := range e.QueueInfo.Objects {
+			c.queueObjects = append(c.queueObjects, resource.ObjectMetadataFromUnstructured(obj))
+		}
+		for _, step := range e.QueueInfo.Steps {
+			var waitSet sets.Set[resource.ObjectMetadata]
+			switch step.Type {
+			case event.StepWait:
+				waitSet = c.waitObjects
+			case event.StepWaitDeletion:
+				waitSet = c.waitDeletionObjects
+			default:
+				continue
+			}
+			for _, obj := range step.Objects {
+				waitSet.Insert(resource.ObjectMetadataFromUnstructured(obj))
+			}
+		}
+	case event.TypeApply:
+		objectResult := c.objectResult(e.ApplyInfo.Object, e.Timestamp)
+		// the final apply event of a replaced object will not hide the replace
+		if objectResult.Action != event.TypeReplace {
+			objectResult.Action = event.TypeApply
+		}
+		objectResult.Reason = e.ApplyInfo.Reason
+		objectResult.Operation = e.ApplyInfo.Operation
+		setOutcome(objectResult, e.ApplyInfo.Status, e.ApplyInfo.Error, OutcomeApplied, OutcomeFailed)
+		// unchanged objects that are already current do not need to wait for their status
+		if e.ApplyInfo.Status == event.StatusSuccessful && e.ApplyInfo.Current {
+			objectResult.Outcome = OutcomeCurrent
+		}
+	case event.TypeReplace:
+		c.objectResult(e.ReplaceInfo.Object, e.Timestamp).Action = event.TypeReplace
+	case event.TypeDiff:
+		objectResult := c.objectResult(e.DiffInfo.Object, e.Timestamp)
+		objectResult.Action = event.TypeDiff
+		objectResult.DiffAction = e.DiffInfo.Action
+		objectResult.Error = e.DiffInfo.Error
+		switch {
+		case e.DiffInfo.Action == event.DiffSkipped:
+			objectResult.Outcome = OutcomeSkipped
+		case e.DiffInfo.Error != nil:
+			objectResult.Outcome = OutcomeFailed
+		default:
+			objectResult.Outcome = OutcomePlanned
+		}
+	case event.TypePrune:
+		objectResult := c.objectResult(e.PruneInfo.Object, e.Timestamp)
+		objectResult.Action = event.TypePrune
+		objectResult.Reason = e.PruneInfo.Reason
+		setOutcome(objectResult, e.PruneInfo.Status, e.PruneInfo.Error, OutcomePruned, OutcomePruneFailed)
+	case event.TypeStatusUpdate:
+		id := e.StatusUpdateInfo.ObjectMetadata
+		objectResult := c.objectResultForID(id, e.Timestamp)
+		objectResult.Message = e.StatusUpdateInfo.Message
+		switch {
+		case e.StatusUpdateInfo.Status == event.StatusFailed && objectResult.Outcome == OutcomePruned:
+			objectResult.Outcome = OutcomePruneFailed
+			objectResult.Error = errors.New(e.StatusUpdateInfo.Message)
+			objectResult.Reason = e.StatusUpdateInfo.Reason
+		case e.StatusUpdateInfo.Status == event.StatusFailed:
+			objectResult.Outcome = OutcomeFailed
+			objectResult.Error = errors.New(e.StatusUpdateInfo.Message)
+			objectResult.Reason = e.StatusUpdateInfo.Reason
+		case e.StatusUpdateInfo.Status == event.StatusSuccessful && objectResult.Outcome == OutcomeApplied:
+			objectResult.Outcome = OutcomeCurrent
+		case e.StatusUpdateInfo.Status == event.StatusSuccessful && objectResult.Outcome == OutcomePruned:
+			c.removedObjects.Insert(id)
+		}
+	}
+}
+
+// setOutcome set the outcome of objectResult following status
+func setOutcome(objectResult *ObjectResult, status event.Status, err error, successful, failed Outcome) {
+	objectResult.Error = err
+	switch status {
+	case event.StatusPending:
+		// an object that is still pending at the end of the run has not been completed in time
+		objectResult.Outcome = OutcomeTimedOut
+	case event.StatusSuccessful:
+		objectResult.Outcome = successful
+	case event.StatusFailed:
+		objectResult.Outcome = failed
+	case event.StatusSkipped:
+		objectResult.Outcome = OutcomeSkipped
+	}
+}
+
+// objectResult return the ObjectResult for obj, see objectResultForID
+func (c *ResultCollector) objectResult(obj *unstructured.Unstructured, timestamp time.Time) *ObjectResult {
+	return c.objectResultForID(resource.ObjectMetadataFromUnstructured(obj), timestamp)
+}
+
+// objectResultForID return the ObjectResult for id, creating it if is the first time that is found, and update its
+// duration with timestamp, or the current time if timestamp is not set
+func (c *ResultCollector) objectResultForID(id resource.ObjectMetadata, timestamp time.Time) *ObjectResult {
+	now := timestamp
+	if now.IsZero() {
+		now = c.now()
+	}
+	idx, found := c.objectsIndex[id]
+	if !found {
+		idx = len(c.result.Objects)
+		c.objectsIndex[id] = idx
+		c.result.Objects = append(c.result.Objects, ObjectResult{Object: id, start: now})
+	}
+
+	objectResult := &c.result.Objects[idx]
+	objectResult.Duration = now.Sub(objectResult.start)
+	return objectResult
+}
+
+// finalize mark as timed out the objects that have not been handled, have not reached their current status or
+// have not been removed if the run has been stopped
+func (c *ResultCollector) finalize() {
+	if !c.stopped {
+		return
+	}
+
+	for _, id := range c.queueObjects {
+		if _, found := c.objectsIndex[id]; !found {
+			c.objectResultForID(id, time.Time{}).Outcome = OutcomeTimedOut
+		}
+	}
+
+	for idx := range c.result.Objects {
+		objectResult := &c.result.Objects[idx]
+		switch {
+		case objectResult.Outcome == OutcomeApplied && c.waitObjects.Has(objectResult.Object):
+			objectResult.Outcome = OutcomeTimedOut
+		case objectResult.Outcome == OutcomePruned && c.waitDeletionObjects.Has(objectResult.Object) &&
+			!c.removedObjects.Has(objectResult.Object):
+			objectResult.Outcome = OutcomeTimedOut
+		}
+	}
+}
+
+// eventError return the error contained in e, status updates are reported with their message
+func eventError(e event.Event) error {
+	switch e.Type {
+	case event.TypeError:
+		return e.ErrorInfo.Error
+	case event.TypeApply:
+		return e.ApplyInfo.Error
+	case event.TypePrune:
+		return e.PruneInfo.Error
+	case event.TypeInventory:
+		return e.InventoryInfo.Error
+	case event.TypeDiff:
+		return e.DiffInfo.Error
+	case event.TypeReplace:
+		return e.ReplaceInfo.Error
+	default:
+		return errors.New(e.String())
+	}
+}
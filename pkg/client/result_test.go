@@ -0,0 +1,308 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestCollectResult(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	job := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "job.yaml"))
+	applyErr := errors.New("apply failed")
+	pruneErr := errors.New("prune failed")
+
+	queue := func(steps ...event.Step) event.Event {
+		return queueEvent(steps)
+	}
+	apply := func(obj *unstructured.Unstructured, status event.Status, err error) event.Event {
+		return event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: obj, Status: status, Error: err}}
+	}
+	prune := func(obj *unstructured.Unstructured, status event.Status, err error) event.Event {
+		return event.Event{Type: event.TypePrune, PruneInfo: event.PruneInfo{Object: obj, Status: status, Error: err}}
+	}
+	statusUpdate := func(obj *unstructured.Unstructured, status event.Status, message string) event.Event {
+		return event.Event{
+			Type: event.TypeStatusUpdate,
+			StatusUpdateInfo: event.StatusUpdateInfo{
+				Status:         status,
+				Message:        message,
+				ObjectMetadata: resource.ObjectMetadataFromUnstructured(obj),
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		events           []event.Event
+		expectedOutcomes map[resource.ObjectMetadata]Outcome
+		expectedErrors   []error
+	}{
+		"successful run": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment}},
+					event.Step{Type: event.StepWait, Objects: []*unstructured.Unstructured{deployment}},
+					event.Step{Type: event.StepPrune, Objects: []*unstructured.Unstructured{namespace}},
+				),
+				apply(deployment, event.StatusPending, nil),
+				apply(deployment, event.StatusSuccessful, nil),
+				statusUpdate(deployment, event.StatusPending, "in progress"),
+				statusUpdate(deployment, event.StatusSuccessful, "current"),
+				prune(namespace, event.StatusPending, nil),
+				prune(namespace, event.StatusSuccessful, nil),
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeCurrent,
+				resource.ObjectMetadataFromUnstructured(namespace):  OutcomePruned,
+			},
+		},
+		"unchanged objects already current": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment}},
+					event.Step{Type: event.StepWait, Objects: []*unstructured.Unstructured{deployment}},
+				),
+				apply(deployment, event.StatusPending, nil),
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Object:    deployment,
+						Status:    event.StatusSuccessful,
+						Operation: event.ApplyUnchanged,
+						Current:   true,
+					},
+				},
+				{Type: event.TypeError, ErrorInfo: event.ErrorInfo{Error: context.DeadlineExceeded}},
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeCurrent,
+			},
+			expectedErrors: []error{context.DeadlineExceeded},
+		},
+		"applied objects without wait": {
+			events: []event.Event{
+				queue(event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment}}),
+				apply(deployment, event.StatusPending, nil),
+				apply(deployment, event.StatusSuccessful, nil),
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeApplied,
+			},
+		},
+		"failed and skipped objects": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment, job}},
+					event.Step{Type: event.StepPrune, Objects: []*unstructured.Unstructured{namespace}},
+				),
+				apply(deployment, event.StatusFailed, applyErr),
+				apply(job, event.StatusSkipped, nil),
+				prune(namespace, event.StatusFailed, pruneErr),
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeFailed,
+				resource.ObjectMetadataFromUnstructured(job):        OutcomeSkipped,
+				resource.ObjectMetadataFromUnstructured(namespace):  OutcomePruneFailed,
+			},
+			expectedErrors: []error{applyErr, pruneErr},
+		},
+		"failed status update": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment}},
+					event.Step{Type: event.StepWait, Objects: []*unstructured.Unstructured{deployment}},
+				),
+				apply(deployment, event.StatusSuccessful, nil),
+				statusUpdate(deployment, event.StatusFailed, "progress deadline exceeded"),
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeFailed,
+			},
+			expectedErrors: []error{errors.New("Deployment.apps nginx: progress deadline exceeded")},
+		},
+		"run stopped by timeout": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{namespace}},
+					event.Step{Type: event.StepWait, Objects: []*unstructured.Unstructured{namespace}},
+					event.Step{Type: event.StepApply, Objects: []*unstructured.Unstructured{deployment}},
+				),
+				apply(namespace, event.StatusSuccessful, nil),
+				statusUpdate(namespace, event.StatusPending, "in progress"),
+				{Type: event.TypeError, ErrorInfo: event.ErrorInfo{Error: context.DeadlineExceeded}},
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(namespace):  OutcomeTimedOut,
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeTimedOut,
+			},
+			expectedErrors: []error{context.DeadlineExceeded},
+		},
+		"run stopped while waiting for deletions": {
+			events: []event.Event{
+				queue(
+					event.Step{Type: event.StepPrune, Objects: []*unstructured.Unstructured{deployment, job}},
+					event.Step{Type: event.StepWaitDeletion, Objects: []*unstructured.Unstructured{deployment, job}},
+				),
+				prune(deployment, event.StatusSuccessful, nil),
+				prune(job, event.StatusSuccessful, nil),
+				statusUpdate(job, event.StatusSuccessful, "removed"),
+				{Type: event.TypeError, ErrorInfo: event.ErrorInfo{Error: context.DeadlineExceeded}},
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomeTimedOut,
+				resource.ObjectMetadataFromUnstructured(job):        OutcomePruned,
+			},
+			expectedErrors: []error{context.DeadlineExceeded},
+		},
+		"preview run": {
+			events: []event.Event{
+				{Type: event.TypeDiff, DiffInfo: event.DiffInfo{Object: deployment, Action: event.DiffUpdate}},
+				{Type: event.TypeDiff, DiffInfo: event.DiffInfo{Object: job, Action: event.DiffFailed, Error: applyErr}},
+				{Type: event.TypeDiff, DiffInfo: event.DiffInfo{Object: namespace, Action: event.DiffSkipped}},
+			},
+			expectedOutcomes: map[resource.ObjectMetadata]Outcome{
+				resource.ObjectMetadataFromUnstructured(deployment): OutcomePlanned,
+				resource.ObjectMetadataFromUnstructured(job):        OutcomeFailed,
+				resource.ObjectMetadataFromUnstructured(namespace):  OutcomeSkipped,
+			},
+			expectedErrors: []error{applyErr},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			result := CollectResult(eventsChannel(testCase.events))
+			outcomes := make(map[resource.ObjectMetadata]Outcome, len(result.Objects))
+			for _, objectResult := range result.Objects {
+				outcomes[objectResult.Object] = objectResult.Outcome
+			}
+
+			assert.Equal(t, testCase.expectedOutcomes, outcomes)
+			assert.Equal(t, testCase.expectedErrors, result.Errors)
+			assert.Equal(t, len(testCase.expectedErrors) == 0, result.Succeeded())
+			if len(testCase.expectedErrors) == 0 {
+				assert.NoError(t, result.Err())
+			} else {
+				assert.Error(t, result.Err())
+			}
+		})
+	}
+}
+
+func TestCollectResultReasons(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	job := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "job.yaml"))
+
+	result := CollectResult(eventsChannel([]event.Event{
+		{
+			Type: event.TypeApply,
+			ApplyInfo: event.ApplyInfo{
+				Object: deployment,
+				Status: event.StatusFailed,
+				Error:  errors.New("forbidden"),
+				Reason: event.ReasonForbidden,
+			},
+		},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: job, Status: event.StatusSuccessful}},
+		{
+			Type: event.TypeStatusUpdate,
+			StatusUpdateInfo: event.StatusUpdateInfo{
+				Status:         event.StatusFailed,
+				Message:        "deadline exceeded",
+				ObjectMetadata: resource.ObjectMetadataFromUnstructured(job),
+				Reason:         event.ReasonTimeout,
+			},
+		},
+		{Type: event.TypePrune, PruneInfo: event.PruneInfo{Object: namespace, Status: event.StatusSuccessful}},
+	}))
+
+	reasons := make(map[resource.ObjectMetadata]event.Reason, len(result.Objects))
+	for _, objectResult := range result.Objects {
+		reasons[objectResult.Object] = objectResult.Reason
+	}
+	assert.Equal(t, map[resource.ObjectMetadata]event.Reason{
+		resource.ObjectMetadataFromUnstructured(deployment): event.ReasonForbidden,
+		resource.ObjectMetadataFromUnstructured(job):        event.ReasonTimeout,
+		resource.ObjectMetadataFromUnstructured(namespace):  event.ReasonNone,
+	}, reasons)
+}
+
+func TestApplierApply(t *testing.T) {
+	t.Parallel()
+
+	testdataPath := "testdata"
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "deployment.yaml"))
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdataPath, "namespace.yaml"))
+	statusEvents := []event.Event{
+		{
+			Type: event.TypeStatusUpdate,
+			StatusUpdateInfo: event.StatusUpdateInfo{
+				Status:         event.StatusSuccessful,
+				ObjectMetadata: resource.ObjectMetadataFromUnstructured(namespace),
+			},
+		},
+	}
+
+	objects := []*unstructured.Unstructured{deployment, namespace}
+	applier := newTestApplier(t, objects, nil, statusEvents, nil, nil, nil)
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	// the deployment will never become current so the run will stop after the timeout
+	result := applier.Apply(withTimeout, objects, ApplierOptions{Timeout: 500 * time.Millisecond})
+	require.NoError(t, withTimeout.Err())
+	require.Len(t, result.Objects, 2)
+
+	assert.Equal(t, resource.ObjectMetadataFromUnstructured(namespace), result.Objects[0].Object)
+	assert.Equal(t, OutcomeCurrent, result.Objects[0].Outcome)
+	assert.Equal(t, resource.ObjectMetadataFromUnstructured(deployment), result.Objects[1].Object)
+	assert.Equal(t, OutcomeTimedOut, result.Objects[1].Outcome)
+	assert.ErrorIs(t, result.Err(), context.DeadlineExceeded)
+	assert.False(t, result.Succeeded())
+	assert.Positive(t, result.Duration)
+}
+
+func eventsChannel(events []event.Event) <-chan event.Event {
+	ch := make(chan event.Event, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
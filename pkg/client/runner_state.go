@@ -19,9 +19,11 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/resource"
 	"github.com/mia-platform/jpl/pkg/runner"
 )
 
@@ -31,6 +33,13 @@ type RunnerState struct {
 	eventChannel chan event.Event
 	manager      *inventory.Manager
 	context      context.Context
+
+	failedObjects sets.Set[resource.ObjectMetadata]
+
+	// skipWaitUnchanged enable the skip of the wait for the objects that have not been changed by the apply
+	// and that are already current
+	skipWaitUnchanged bool
+	currentObjects    sets.Set[resource.ObjectMetadata]
 }
 
 func (s *RunnerState) GetContext() context.Context {
@@ -41,7 +50,18 @@ func (s *RunnerState) SendEvent(e event.Event) {
 	switch e.Type {
 	case event.TypeApply:
 		s.registerEventInManager(e.Type, e.ApplyInfo.Status, e.ApplyInfo.Object)
+		// objects skipped with an error are considered failed too for propagating the failure to their dependents
+		if e.ApplyInfo.Error != nil {
+			s.registerFailure(e.ApplyInfo.Object)
+		}
+		if s.skipWaitUnchanged && e.ApplyInfo.Status == event.StatusSuccessful && e.ApplyInfo.Current {
+			s.registerCurrent(e.ApplyInfo.Object)
+		}
 	case event.TypePrune:
+		if e.PruneInfo.Status == event.StatusSkipped && e.PruneInfo.Kept {
+			s.manager.SetKeptDelete(e.PruneInfo.Object)
+			break
+		}
 		s.registerEventInManager(e.Type, e.PruneInfo.Status, e.PruneInfo.Object)
 	}
 	s.eventChannel <- e
@@ -59,9 +79,34 @@ func (s *RunnerState) registerEventInManager(eventType event.Type, status event.
 		s.manager.SetSuccessfullDelete(obj)
 	case eventType == event.TypePrune && status == event.StatusFailed:
 		s.manager.SetFailedDelete(obj)
+	case eventType == event.TypePrune && status == event.StatusSkipped:
+		s.manager.SetSkippedDelete(obj)
 	}
 }
 
 func (s *RunnerState) SkipWaitCurrentStatus(obj *unstructured.Unstructured) bool {
-	return s.manager.IsFailedApply(obj) || s.manager.IsSkipped(obj)
+	return s.manager.IsFailedApply(obj) || s.manager.IsSkipped(obj) || s.manager.IsFailedDelete(obj) ||
+		s.manager.IsSkippedDelete(obj) || s.manager.IsKeptDelete(obj) || s.currentObjects.Has(resource.ObjectMetadataFromUnstructured(obj))
+}
+
+func (s *RunnerState) registerCurrent(obj *unstructured.Unstructured) {
+	if s.currentObjects == nil {
+		s.currentObjects = sets.New[resource.ObjectMetadata]()
+	}
+	s.currentObjects.Insert(resource.ObjectMetadataFromUnstructured(obj))
+}
+
+func (s *RunnerState) registerFailure(obj *unstructured.Unstructured) {
+	if s.failedObjects == nil {
+		s.failedObjects = sets.New[resource.ObjectMetadata]()
+	}
+	s.failedObjects.Insert(resource.ObjectMetadataFromUnstructured(obj))
+}
+
+func (s *RunnerState) IsFailed(id resource.ObjectMetadata) bool {
+	return s.failedObjects.Has(id)
+}
+
+func (s *RunnerState) HasFailures() bool {
+	return s.failedObjects.Len() > 0
 }
@@ -0,0 +1,70 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestRunnerStateSkipWaitUnchanged(t *testing.T) {
+	t.Parallel()
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join("testdata", "deployment.yaml"))
+	currentEvent := event.Event{
+		Type: event.TypeApply,
+		ApplyInfo: event.ApplyInfo{
+			Object:    deployment,
+			Status:    event.StatusSuccessful,
+			Operation: event.ApplyUnchanged,
+			Current:   true,
+		},
+	}
+
+	testCases := map[string]struct {
+		skipWaitUnchanged bool
+		expectedSkip      bool
+	}{
+		"wait unchanged objects by default": {},
+		"skip wait of unchanged current objects": {
+			skipWaitUnchanged: true,
+			expectedSkip:      true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			state := &RunnerState{
+				eventChannel:      make(chan event.Event, 1),
+				manager:           inventory.NewManager(&fakeinventory.Inventory{}, nil),
+				context:           t.Context(),
+				skipWaitUnchanged: testCase.skipWaitUnchanged,
+			}
+
+			state.SendEvent(currentEvent)
+			assert.Equal(t, testCase.expectedSkip, state.SkipWaitCurrentStatus(deployment))
+		})
+	}
+}
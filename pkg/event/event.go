@@ -17,6 +17,7 @@ package event
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -36,6 +37,11 @@ const (
 	TypePrune
 	TypeInventory
 	TypeStatusUpdate
+	TypeDiff
+	TypeReplace
+	TypeTask
+	TypeWarning
+	TypeRetry
 )
 
 // Status determine the status of events that are available.
@@ -50,11 +56,55 @@ const (
 	StatusSkipped
 )
 
+// DiffAction determine what will happen to an object when the changes are applied.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=DiffAction -trimprefix=Diff
+type DiffAction int
+
+const (
+	DiffCreate DiffAction = iota
+	DiffUpdate
+	DiffUnchanged
+	DiffPrune
+	// DiffFailed is used when the changes cannot be computed or the server rejected the object, its apply will fail
+	DiffFailed
+	// DiffSkipped is used for objects that will be left untouched because they are filtered out or protected
+	DiffSkipped
+)
+
+// ApplyOperation determine what has been done to an object by a successful apply.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=ApplyOperation -trimprefix=Apply
+type ApplyOperation int
+
+const (
+	ApplyUnknown ApplyOperation = iota
+	ApplyCreated
+	ApplyConfigured
+	ApplyUnchanged
+)
+
+// StepType determine the type of steps that a queue can execute.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=StepType -trimprefix=Step
+type StepType int
+
+const (
+	StepApply StepType = iota
+	StepWait
+	StepPrune
+	StepWaitDeletion
+	StepInventory
+)
+
 // Event is the basic block for encapsulate the progression of a task or queue during its execution, more state
 // can be encapsulated extending this struct but
 type Event struct {
 	Type Type
 
+	// Timestamp is the time when the event has been generated
+	Timestamp time.Time
+
 	// ErrorInfo contains info for a TypeError event
 	ErrorInfo ErrorInfo
 
@@ -72,6 +122,21 @@ type Event struct {
 
 	// StatusUpdateInfo contains info for a TypeStatusUpdate event
 	StatusUpdateInfo StatusUpdateInfo
+
+	// DiffInfo contains info for a TypeDiff event
+	DiffInfo DiffInfo
+
+	// ReplaceInfo contains info for a TypeReplace event
+	ReplaceInfo ReplaceInfo
+
+	// TaskInfo contains info for a TypeTask event
+	TaskInfo TaskInfo
+
+	// WarningInfo contains info for a TypeWarning event
+	WarningInfo WarningInfo
+
+	// RetryInfo contains info for a TypeRetry event
+	RetryInfo RetryInfo
 }
 
 // IsErrorEvent can be used to check if the error contains some type of error
@@ -87,6 +152,10 @@ func (e Event) IsErrorEvent() bool {
 		return e.InventoryInfo.Error != nil
 	case TypeStatusUpdate:
 		return e.StatusUpdateInfo.Status == StatusFailed
+	case TypeDiff:
+		return e.DiffInfo.Error != nil
+	case TypeReplace:
+		return e.ReplaceInfo.Error != nil
 	default:
 		return false
 	}
@@ -107,6 +176,16 @@ func (e Event) String() string {
 		return e.InventoryInfo.String()
 	case TypeStatusUpdate:
 		return e.StatusUpdateInfo.String()
+	case TypeDiff:
+		return e.DiffInfo.String()
+	case TypeReplace:
+		return e.ReplaceInfo.String()
+	case TypeTask:
+		return e.TaskInfo.String()
+	case TypeWarning:
+		return e.WarningInfo.String()
+	case TypeRetry:
+		return e.RetryInfo.String()
 	default:
 		return "event type unknown"
 	}
@@ -122,6 +201,14 @@ func (i ErrorInfo) String() string {
 
 type QueueInfo struct {
 	Objects []*unstructured.Unstructured
+	// Steps contains the steps that the queue will execute in order
+	Steps []Step
+}
+
+// Step contains the objects that will be handled together by a queue
+type Step struct {
+	Type    StepType
+	Objects []*unstructured.Unstructured
 }
 
 func (i QueueInfo) String() string {
@@ -137,6 +224,27 @@ type ApplyInfo struct {
 	Object *unstructured.Unstructured
 	Status Status
 	Error  error
+	// Conflicts contains the fields owned by other field managers that have blocked the apply
+	Conflicts []Conflict
+	// Reason classify the failure of the apply, it is ReasonNone if the apply has not failed
+	Reason Reason
+	// Causes contains the field level causes of the failure returned by the api-server
+	Causes []Cause
+	// Operation is what has been done to the object by a successful apply, it is ApplyUnknown if the remote state
+	// of the object before the apply is not known
+	Operation ApplyOperation
+	// Current is true if the object has not been changed by the apply and has already reached its current status
+	Current bool
+}
+
+// Conflict describe a field of an object that is managed by another field manager
+type Conflict struct {
+	// Field is the path of the conflicting field
+	Field string
+	// Manager is the name of the field manager that owns the field
+	Manager string
+	// Message is the original message returned by the api-server
+	Message string
 }
 
 func (i ApplyInfo) String() string {
@@ -145,8 +253,11 @@ func (i ApplyInfo) String() string {
 	case StatusPending:
 		return objID + ": apply started..."
 	case StatusSuccessful:
-		return objID + ": applied successfully"
+		return objID + ": " + applyOperationMessages[i.Operation]
 	case StatusSkipped:
+		if i.Error != nil {
+			return objID + ": apply skipped: " + i.Error.Error()
+		}
 		return objID + ": apply skipped"
 	case StatusFailed:
 		return objID + ": failed to apply: " + i.Error.Error()
@@ -155,10 +266,25 @@ func (i ApplyInfo) String() string {
 	}
 }
 
+// applyOperationMessages contains the messages used for describing a successful apply
+var applyOperationMessages = map[ApplyOperation]string{
+	ApplyUnknown:    "applied successfully",
+	ApplyCreated:    "created",
+	ApplyConfigured: "configured",
+	ApplyUnchanged:  "unchanged",
+}
+
 type PruneInfo struct {
 	Object *unstructured.Unstructured
 	Status Status
 	Error  error
+	// Kept is true if a skipped object continues to be tracked by the inventory for being pruned in a future run,
+	// instead of being removed from it
+	Kept bool
+	// Reason classify the failure of the prune, it is ReasonNone if the prune has not failed
+	Reason Reason
+	// Causes contains the field level causes of the failure returned by the api-server
+	Causes []Cause
 }
 
 func (i PruneInfo) String() string {
@@ -168,6 +294,11 @@ func (i PruneInfo) String() string {
 		return objID + ": prune started..."
 	case StatusSuccessful:
 		return objID + ": pruned successfully"
+	case StatusSkipped:
+		if i.Error != nil {
+			return objID + ": prune skipped: " + i.Error.Error()
+		}
+		return objID + ": prune skipped"
 	case StatusFailed:
 		return objID + ": failed to prune: " + i.Error.Error()
 	default:
@@ -175,6 +306,94 @@ func (i PruneInfo) String() string {
 	}
 }
 
+// ReplaceInfo contains info about an object that is deleted and created again because its apply has failed
+// for changes to immutable fields
+type ReplaceInfo struct {
+	Object *unstructured.Unstructured
+	Status Status
+	Error  error
+}
+
+func (i ReplaceInfo) String() string {
+	objID := identifierFromObject(i.Object)
+	switch i.Status {
+	case StatusPending:
+		return objID + ": replace started..."
+	case StatusSuccessful:
+		return objID + ": replaced successfully"
+	case StatusFailed:
+		return objID + ": failed to replace: " + i.Error.Error()
+	default:
+		return objID + ": replace status unknown"
+	}
+}
+
+// TaskInfo contains info about the start and the end of a task executed by the runner
+type TaskInfo struct {
+	// Kind is the type of work done by the task
+	Kind string
+	// Group is the index of the task between the ones of the same kind in the queue, for apply and wait tasks it
+	// correspond to the index of the dependency group of their objects
+	Group int
+	// Objects is the number of objects handled by the task
+	Objects int
+	// Status is StatusPending when the task is started and StatusSuccessful when it has finished
+	Status Status
+	// Duration is the time taken by the task, set only when it has finished
+	Duration time.Duration
+}
+
+func (i TaskInfo) String() string {
+	taskID := fmt.Sprintf("%s task %d (%d objects)", i.Kind, i.Group, i.Objects)
+	switch i.Status {
+	case StatusPending:
+		return taskID + ": started..."
+	case StatusSuccessful:
+		return fmt.Sprintf("%s: finished in %s", taskID, i.Duration)
+	default:
+		return taskID + ": status unknown"
+	}
+}
+
+// WarningInfo contains a non fatal problem found during the run, like the warnings returned by the api-server
+// for deprecated api versions or unknown fields
+type WarningInfo struct {
+	// Object is the object that has caused the warning, it can be nil if the warning is not related to an object
+	Object  *unstructured.Unstructured
+	Message string
+}
+
+func (i WarningInfo) String() string {
+	if i.Object == nil {
+		return "warning: " + i.Message
+	}
+	return identifierFromObject(i.Object) + ": warning: " + i.Message
+}
+
+// RetryInfo contains info about a request to the api-server that has failed for a transient error and that will
+// be tried again after Delay
+type RetryInfo struct {
+	// Object is the object handled by the request, it can be nil if the request is not related to an object
+	Object *unstructured.Unstructured
+	// Operation describe the request that has failed
+	Operation string
+	// Attempt is the number of the attempt that has failed, starting from 1
+	Attempt int
+	// MaxAttempts is the maximum number of attempts that will be made
+	MaxAttempts int
+	// Delay is the time that will be waited before the next attempt
+	Delay time.Duration
+	Error error
+}
+
+func (i RetryInfo) String() string {
+	message := fmt.Sprintf("%s attempt %d of %d failed, retrying in %s: %s", i.Operation, i.Attempt, i.MaxAttempts, i.Delay, i.Error)
+	if i.Object == nil {
+		return message
+	}
+	return identifierFromObject(i.Object) + ": " + message
+}
+
 type InventoryInfo struct {
 	Status Status
 	Error  error
@@ -202,6 +421,8 @@ type StatusUpdateInfo struct {
 	Status         Status
 	Message        string
 	ObjectMetadata resource.ObjectMetadata
+	// Reason classify why the object has failed to reach its current status, it is ReasonNone if it has not failed
+	Reason Reason
 }
 
 func (i StatusUpdateInfo) String() string {
@@ -211,3 +432,44 @@ func (i StatusUpdateInfo) String() string {
 	}
 	return fmt.Sprintf("%s %s: %s", gk.String(), i.ObjectMetadata.Name, i.Message)
 }
+
+type DiffInfo struct {
+	Object *unstructured.Unstructured
+	Action DiffAction
+	// Diff contains the changes in unified format between the remote object and the one that will be applied
+	Diff  string
+	Error error
+}
+
+func (i DiffInfo) String() string {
+	objID := identifierFromObject(i.Object)
+	if i.Action == DiffSkipped {
+		if i.Error != nil {
+			return objID + ": will be skipped: " + i.Error.Error()
+		}
+		return objID + ": will be skipped"
+	}
+
+	if i.Error != nil {
+		return objID + ": failed to compute diff: " + i.Error.Error()
+	}
+
+	var message string
+	switch i.Action {
+	case DiffCreate:
+		message = objID + ": will be created"
+	case DiffUpdate:
+		message = objID + ": will be updated"
+	case DiffUnchanged:
+		message = objID + ": unchanged"
+	case DiffPrune:
+		message = objID + ": will be pruned"
+	default:
+		message = objID + ": diff status unknown"
+	}
+
+	if len(i.Diff) > 0 {
+		message += "\n" + i.Diff
+	}
+	return message
+}
@@ -0,0 +1,127 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// Reason classify the cause of the failure reported by an event.
+//
+//go:generate ${TOOLS_BIN}/stringer -type=Reason -trimprefix=Reason
+type Reason int
+
+const (
+	// ReasonNone is used for events that don't report a failure
+	ReasonNone Reason = iota
+	// ReasonUnknown is used for failures that cannot be classified
+	ReasonUnknown
+	// ReasonForbidden is used when the credentials used are not valid or don't have the required permissions
+	ReasonForbidden
+	// ReasonInvalid is used when the object is rejected by the api-server validation
+	ReasonInvalid
+	// ReasonConflict is used when the object conflicts with the remote state, like for fields owned by other managers
+	ReasonConflict
+	// ReasonNotFound is used when the object, its namespace or its resource type cannot be found
+	ReasonNotFound
+	// ReasonTimeout is used when the operation has not been completed in time
+	ReasonTimeout
+	// ReasonWebhookDenied is used when the object is rejected by an admission webhook
+	ReasonWebhookDenied
+	// ReasonQuotaExceeded is used when the object will exceed a resource quota of its namespace
+	ReasonQuotaExceeded
+	// ReasonUnavailable is used when the api-server cannot handle the request at the moment
+	ReasonUnavailable
+)
+
+// Cause is a field level cause of a failure returned by the api-server
+type Cause struct {
+	// Type is the machine readable type of the cause, like FieldValueRequired or FieldValueInvalid
+	Type string
+	// Field is the path of the field that has caused the failure, if any
+	Field string
+	// Message is the human readable description of the cause
+	Message string
+}
+
+const (
+	webhookDeniedMessage = "denied the request"
+	quotaExceededMessage = "exceeded quota"
+)
+
+// ReasonForError return the Reason of the failure described by err, or ReasonNone if err is nil
+func ReasonForError(err error) Reason {
+	switch {
+	case err == nil:
+		return ReasonNone
+	case isWebhookDenied(err):
+		return ReasonWebhookDenied
+	case apierrors.IsForbidden(err) && strings.Contains(err.Error(), quotaExceededMessage):
+		return ReasonQuotaExceeded
+	case apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err):
+		return ReasonForbidden
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+		return ReasonInvalid
+	case apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err):
+		return ReasonConflict
+	case apierrors.IsNotFound(err) || apierrors.IsGone(err) || meta.IsNoMatchError(err):
+		return ReasonNotFound
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	case apierrors.IsServiceUnavailable(err) || apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err):
+		return ReasonUnavailable
+	default:
+		return ReasonUnknown
+	}
+}
+
+// isWebhookDenied return true if err has been returned by an admission webhook that has rejected the request,
+// the api-server will use the status code chosen by the webhook, so only the message can be used for detecting it
+func isWebhookDenied(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	message := statusErr.Status().Message
+	return strings.HasPrefix(message, "admission webhook ") && strings.Contains(message, webhookDeniedMessage)
+}
+
+// CausesForError return the field level causes contained in err if it has been returned by the api-server
+func CausesForError(err error) []Cause {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return nil
+	}
+
+	var causes []Cause
+	for _, cause := range statusErr.Status().Details.Causes {
+		causes = append(causes, Cause{
+			Type:    string(cause.Type),
+			Field:   cause.Field,
+			Message: cause.Message,
+		})
+	}
+
+	return causes
+}
@@ -0,0 +1,141 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestReasonForError(t *testing.T) {
+	t.Parallel()
+
+	deployGR := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	webhookErr := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    400,
+		Reason:  metav1.StatusReasonBadRequest,
+		Message: `admission webhook "policy.example.com" denied the request: missing required label`,
+	}}
+
+	tests := map[string]struct {
+		err      error
+		expected Reason
+	}{
+		"nil error": {
+			expected: ReasonNone,
+		},
+		"generic error": {
+			err:      errors.New("generic error"),
+			expected: ReasonUnknown,
+		},
+		"forbidden": {
+			err:      apierrors.NewForbidden(deployGR, "nginx", errors.New("user cannot patch")),
+			expected: ReasonForbidden,
+		},
+		"unauthorized": {
+			err:      apierrors.NewUnauthorized("invalid token"),
+			expected: ReasonForbidden,
+		},
+		"quota exceeded": {
+			err:      apierrors.NewForbidden(deployGR, "nginx", errors.New("exceeded quota: compute, requested: cpu=2")),
+			expected: ReasonQuotaExceeded,
+		},
+		"invalid": {
+			err: apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "nginx", field.ErrorList{
+				field.Required(field.NewPath("spec", "selector"), ""),
+			}),
+			expected: ReasonInvalid,
+		},
+		"conflict": {
+			err:      apierrors.NewConflict(deployGR, "nginx", errors.New("object has been modified")),
+			expected: ReasonConflict,
+		},
+		"not found": {
+			err:      apierrors.NewNotFound(deployGR, "nginx"),
+			expected: ReasonNotFound,
+		},
+		"missing mapping": {
+			err:      &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "example.com", Kind: "Custom"}},
+			expected: ReasonNotFound,
+		},
+		"server timeout": {
+			err:      apierrors.NewServerTimeout(deployGR, "patch", 1),
+			expected: ReasonTimeout,
+		},
+		"context deadline": {
+			err:      fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expected: ReasonTimeout,
+		},
+		"webhook denied": {
+			err:      webhookErr,
+			expected: ReasonWebhookDenied,
+		},
+		"wrapped webhook denied": {
+			err:      fmt.Errorf("failed to apply: %w", webhookErr),
+			expected: ReasonWebhookDenied,
+		},
+		"service unavailable": {
+			err:      apierrors.NewServiceUnavailable("api-server is shutting down"),
+			expected: ReasonUnavailable,
+		},
+		"too many requests": {
+			err:      apierrors.NewTooManyRequests("too many requests", 1),
+			expected: ReasonUnavailable,
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, ReasonForError(testCase.err))
+		})
+	}
+}
+
+func TestCausesForError(t *testing.T) {
+	t.Parallel()
+
+	invalidErr := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "nginx", field.ErrorList{
+		field.Required(field.NewPath("spec", "selector"), ""),
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+
+	assert.Equal(t, []Cause{
+		{
+			Type:    "FieldValueRequired",
+			Field:   "spec.selector",
+			Message: "Required value",
+		},
+		{
+			Type:    "FieldValueInvalid",
+			Field:   "spec.replicas",
+			Message: "Invalid value: -1: must be greater than or equal to 0",
+		},
+	}, CausesForError(fmt.Errorf("failed to apply: %w", invalidErr)))
+	assert.Nil(t, CausesForError(errors.New("generic error")))
+	assert.Nil(t, CausesForError(apierrors.NewServiceUnavailable("api-server is shutting down")))
+	assert.Nil(t, CausesForError(nil))
+}
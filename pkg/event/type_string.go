@@ -14,11 +14,16 @@ func _() {
 	_ = x[TypePrune-3]
 	_ = x[TypeInventory-4]
 	_ = x[TypeStatusUpdate-5]
+	_ = x[TypeDiff-6]
+	_ = x[TypeReplace-7]
+	_ = x[TypeTask-8]
+	_ = x[TypeWarning-9]
+	_ = x[TypeRetry-10]
 }
 
-const _Type_name = "ErrorQueueApplyPruneInventoryStatusUpdate"
+const _Type_name = "ErrorQueueApplyPruneInventoryStatusUpdateDiffReplaceTaskWarningRetry"
 
-var _Type_index = [...]uint8{0, 5, 10, 15, 20, 29, 41}
+var _Type_index = [...]uint8{0, 5, 10, 15, 20, 29, 41, 45, 52, 56, 63, 68}
 
 func (i Type) String() string {
 	idx := int(i) - 0
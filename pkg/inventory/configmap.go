@@ -31,7 +31,7 @@ import (
 )
 
 // keep it to always check if configMapStore implement correctly the Store interface
-var _ Store = &configMapStore{}
+var _ IdentifiableStore = &configMapStore{}
 
 // configMapStore is an inventory store backed by a ConfigMap saved on the remote server where the
 // operations are performed. It only keep track of what resources have been deployed but not their contents.
@@ -60,6 +60,11 @@ func NewConfigMapStore(factory util.ClientFactory, name, namespace, fieldManager
 	}, nil
 }
 
+// ID implement IdentifiableStore interface
+func (s *configMapStore) ID() string {
+	return s.namespace + "/" + s.name
+}
+
 // Save implement Store interface
 func (s *configMapStore) Save(ctx context.Context, dryRun bool) error {
 	opts := metav1.ApplyOptions{
@@ -58,6 +58,7 @@ func TestNewConfigMapStore(t *testing.T) {
 	assert.Equal(t, name, cmStore.name)
 	assert.Equal(t, namespace, cmStore.namespace)
 	assert.Equal(t, fieldManager, cmStore.fieldManager)
+	assert.Equal(t, "test-namespace/test-name", IDOf(store))
 }
 
 func TestLoad(t *testing.T) {
@@ -27,10 +27,14 @@ import (
 )
 
 // keep it to always check if Inventory implement correctly the Store interface
-var _ inventory.Store = &Inventory{}
+var _ inventory.IdentifiableStore = &Inventory{}
 
 type Inventory struct {
+	// InventoryID will be returned as the identifier of the inventory
+	InventoryID      string
 	InventoryObjects []*unstructured.Unstructured
+	// Objects contains the objects passed to the last call of SetObjects
+	Objects sets.Set[*unstructured.Unstructured]
 
 	SaveFunc func(context.Context, bool) error
 
@@ -42,6 +46,11 @@ type Inventory struct {
 	DeleteErr error
 }
 
+// ID implement IdentifiableStore interface
+func (i *Inventory) ID() string {
+	return i.InventoryID
+}
+
 // Load implement Store interface
 func (i *Inventory) Load(_ context.Context) (sets.Set[resource.ObjectMetadata], error) {
 	if i.LoadErr != nil {
@@ -79,7 +88,9 @@ func (i *Inventory) Delete(_ context.Context, _ bool) error {
 }
 
 // SetObjects implement Store interface
-func (i *Inventory) SetObjects(_ sets.Set[*unstructured.Unstructured]) {}
+func (i *Inventory) SetObjects(objects sets.Set[*unstructured.Unstructured]) {
+	i.Objects = objects
+}
 
 // Diff implement Store interface
 func (i *Inventory) Diff(ctx context.Context, objects []*unstructured.Unstructured) (sets.Set[resource.ObjectMetadata], error) {
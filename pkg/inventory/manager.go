@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 )
 
 type objectStatus int
@@ -32,11 +33,16 @@ const (
 	objectStatusDeleteSuccessfull
 	objectStatusDeleteFailed
 	objectStatusSkipped
+	objectStatusDeleteSkipped
+	objectStatusDeleteKept
 )
 
 // Manager will save and manage the current state of objects for
 type Manager struct {
 	Inventory Store
+	// RetryPolicy if set is used for retrying the save and the deletion of the inventory when they fail for
+	// transient errors
+	RetryPolicy *retry.Policy
 
 	startingObjects []*unstructured.Unstructured
 	objectStatuses  map[*unstructured.Unstructured]objectStatus
@@ -98,6 +104,48 @@ func (m *Manager) SetFailedDelete(obj *unstructured.Unstructured) {
 	m.setStatus(obj, objectStatusDeleteFailed)
 }
 
+// IsFailedDelete return if the passed in object has been marked as failed to delete from the manager
+func (m *Manager) IsFailedDelete(obj *unstructured.Unstructured) bool {
+	status, found := m.objectStatuses[obj]
+	if !found {
+		return false
+	}
+
+	return status == objectStatusDeleteFailed
+}
+
+// SetSkippedDelete keep track of the passed objs as not deleted because they have been orphaned,
+// these objects will not be tracked anymore in the inventory
+func (m *Manager) SetSkippedDelete(obj *unstructured.Unstructured) {
+	m.setStatus(obj, objectStatusDeleteSkipped)
+}
+
+// IsSkippedDelete return if the passed in object has been marked as skipped during deletion from the manager
+func (m *Manager) IsSkippedDelete(obj *unstructured.Unstructured) bool {
+	status, found := m.objectStatuses[obj]
+	if !found {
+		return false
+	}
+
+	return status == objectStatusDeleteSkipped
+}
+
+// SetKeptDelete keep track of the passed objs as not deleted because the prune has been stopped, for example
+// by a previous failure, these objects will continue to be tracked in the inventory
+func (m *Manager) SetKeptDelete(obj *unstructured.Unstructured) {
+	m.setStatus(obj, objectStatusDeleteKept)
+}
+
+// IsKeptDelete return if the passed in object has been marked as kept during deletion from the manager
+func (m *Manager) IsKeptDelete(obj *unstructured.Unstructured) bool {
+	status, found := m.objectStatuses[obj]
+	if !found {
+		return false
+	}
+
+	return status == objectStatusDeleteKept
+}
+
 // SetSkipped keep track of the passed objs as skipped
 func (m *Manager) SetSkipped(obj *unstructured.Unstructured) {
 	m.setStatus(obj, objectStatusSkipped)
@@ -122,6 +170,8 @@ func (m *Manager) SaveCurrentInventoryState(ctx context.Context, dryRun bool) er
 	newInventory = newInventory.Union(m.objectsForStatus(objectStatusApplySuccessfull))
 	// add all object that failed to be pruned for not leaving abandoned objects in the cluster
 	newInventory = newInventory.Union(m.objectsForStatus(objectStatusDeleteFailed))
+	// add all object that have not been pruned, they must be pruned in a future run
+	newInventory = newInventory.Union(m.objectsForStatus(objectStatusDeleteKept))
 
 	// add all objects that failed to apply only if they are already been tracked
 	applyFailed := m.intersectedObjects(m.objectsForStatus(objectStatusApplyFailed), m.startingObjects)
@@ -131,19 +181,60 @@ func (m *Manager) SaveCurrentInventoryState(ctx context.Context, dryRun bool) er
 	skipped := m.intersectedObjects(m.objectsForStatus(objectStatusSkipped), m.startingObjects)
 	newInventory = newInventory.Union(skipped)
 
+	// add all tracked objects that have not been touched, for example if the run has been stopped earlier
+	newInventory = newInventory.Union(m.untouchedObjects())
+
 	m.Inventory.SetObjects(newInventory)
-	return m.Inventory.Save(ctx, dryRun)
+	return m.RetryPolicy.Do(ctx, "inventory save", func() error {
+		return m.Inventory.Save(ctx, dryRun)
+	})
+}
+
+// CanDeleteRemoteInventory return true if all the objects tracked by the inventory have been removed from it,
+// and so it can be deleted from the remote server
+func (m *Manager) CanDeleteRemoteInventory() bool {
+	// if applies or deletes are failing we cannot remove the inventory for not leaving objects stranding in the cluster
+	if len(m.objectsForStatus(objectStatusDeleteFailed)) != 0 {
+		return false
+	}
+
+	// objects that have not been pruned are still tracked by the inventory
+	if len(m.objectsForStatus(objectStatusDeleteKept)) != 0 {
+		return false
+	}
+
+	// if some objects has not been deleted, for example because the run has been stopped earlier, the inventory
+	// must be kept for removing them in the future
+	return len(m.untouchedObjects()) == 0
 }
 
 // DeleteRemoteInventoryIfPossible calling this method will remove the remote invetory storage if possible.
 // If any object has been marked as failed to delete for any reason, we cannot remove
 func (m *Manager) DeleteRemoteInventoryIfPossible(ctx context.Context, dryRun bool) error {
-	// if applies or deletes are failing we cannot remove the inventory for not leaving objects stranding in the cluster
-	if len(m.objectsForStatus(objectStatusDeleteFailed)) != 0 {
+	if !m.CanDeleteRemoteInventory() {
 		return nil
 	}
 
-	return m.Inventory.Delete(ctx, dryRun)
+	return m.RetryPolicy.Do(ctx, "inventory delete", func() error {
+		return m.Inventory.Delete(ctx, dryRun)
+	})
+}
+
+// untouchedObjects return the starting objects that don't have any status saved
+func (m *Manager) untouchedObjects() sets.Set[*unstructured.Unstructured] {
+	touchedSet := make(sets.Set[resource.ObjectMetadata], len(m.objectStatuses))
+	for obj := range m.objectStatuses {
+		touchedSet.Insert(resource.ObjectMetadataFromUnstructured(obj))
+	}
+
+	untouched := sets.New[*unstructured.Unstructured]()
+	for _, obj := range m.startingObjects {
+		if !touchedSet.Has(resource.ObjectMetadataFromUnstructured(obj)) {
+			untouched.Insert(obj)
+		}
+	}
+
+	return untouched
 }
 
 // intersectedObjects return objects that are contained in first and second
@@ -72,6 +72,18 @@ func TestObjectSatus(t *testing.T) {
 	assert.Equal(t, sets.New(clustercr), manager.objectsForStatus(objectStatusApplySuccessfull))
 	assert.Equal(t, sets.New(clustercrd), manager.objectsForStatus(objectStatusDeleteSuccessfull))
 	assert.Equal(t, sets.New(cronjob), manager.objectsForStatus(objectStatusSkipped))
+	assert.True(t, manager.IsFailedDelete(namespace))
+	assert.False(t, manager.IsFailedDelete(deployment))
+
+	manager.SetSkippedDelete(namespace)
+	assert.True(t, manager.IsSkippedDelete(namespace))
+	assert.False(t, manager.IsFailedDelete(namespace))
+	assert.Equal(t, sets.New(namespace), manager.objectsForStatus(objectStatusDeleteSkipped))
+	manager.SetKeptDelete(namespace)
+	assert.True(t, manager.IsKeptDelete(namespace))
+	assert.False(t, manager.IsSkippedDelete(namespace))
+	assert.Equal(t, sets.New(namespace), manager.objectsForStatus(objectStatusDeleteKept))
+	manager.SetFailedDelete(namespace)
 
 	manager.SetSuccessfullApply(deployment)
 	assert.Len(t, manager.objectStatuses, 5)
@@ -206,6 +218,75 @@ func TestSaveConfigMap(t *testing.T) {
 				pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "cluster-crd.yaml")),
 			},
 		},
+		"save inventory with previous data, remove skipped deletions": {
+			client: &fake.RESTClient{
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					switch {
+					case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/namespaces/test/configmaps/test":
+						data, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						decoder := pkgtesting.Codecs.UniversalDecoder()
+						var configMap corev1.ConfigMap
+						err = runtime.DecodeInto(decoder, data, &configMap)
+						require.NoError(t, err)
+						assert.Equal(t, map[string]string{
+							"_nginx_apps_Deployment": "",
+						}, configMap.Data)
+						return &http.Response{
+							StatusCode: http.StatusNoContent,
+							Header:     pkgtesting.DefaultHeaders(),
+							Body:       io.NopCloser(bytes.NewBuffer(data)),
+						}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("no calls are expected here")
+					}
+				}),
+			},
+			currentStatus: map[*unstructured.Unstructured]objectStatus{
+				deployment: objectStatusApplySuccessfull,
+				namespace:  objectStatusDeleteSkipped,
+			},
+			startingObjects: []*unstructured.Unstructured{
+				pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespace.yaml")),
+			},
+		},
+		"save inventory keeping tracked objects without status": {
+			client: &fake.RESTClient{
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					switch {
+					case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/namespaces/test/configmaps/test":
+						data, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						decoder := pkgtesting.Codecs.UniversalDecoder()
+						var configMap corev1.ConfigMap
+						err = runtime.DecodeInto(decoder, data, &configMap)
+						require.NoError(t, err)
+						assert.Equal(t, map[string]string{
+							"_nginx_apps_Deployment": "",
+							"_test__Namespace":       "",
+						}, configMap.Data)
+						return &http.Response{
+							StatusCode: http.StatusNoContent,
+							Header:     pkgtesting.DefaultHeaders(),
+							Body:       io.NopCloser(bytes.NewBuffer(data)),
+						}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("no calls are expected here")
+					}
+				}),
+			},
+			currentStatus: map[*unstructured.Unstructured]objectStatus{
+				deployment: objectStatusApplySuccessfull,
+				cronjob:    objectStatusDeleteSuccessfull,
+			},
+			startingObjects: []*unstructured.Unstructured{
+				pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployment.yaml")),
+				pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "cronjob.yaml")),
+				pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespace.yaml")),
+			},
+		},
 		"dry run save inventory": {
 			client: &fake.RESTClient{
 				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
@@ -279,10 +360,11 @@ func TestDeleteConfigMap(t *testing.T) {
 	service := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "service.yaml"))
 
 	testCases := map[string]struct {
-		client        *fake.RESTClient
-		currentStatus map[*unstructured.Unstructured]objectStatus
-		expectErr     bool
-		dryRun        bool
+		client          *fake.RESTClient
+		startingObjects []*unstructured.Unstructured
+		currentStatus   map[*unstructured.Unstructured]objectStatus
+		expectErr       bool
+		dryRun          bool
 	}{
 		"delete inventory": {
 			client: &fake.RESTClient{
@@ -352,6 +434,30 @@ func TestDeleteConfigMap(t *testing.T) {
 				service:    objectStatusApplySuccessfull,
 			},
 		},
+		"avoid deletion because of objects not pruned": {
+			client: &fake.RESTClient{
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+					return nil, errors.New("no calls are expected here")
+				}),
+			},
+			currentStatus: map[*unstructured.Unstructured]objectStatus{
+				deployment: objectStatusDeleteKept,
+				service:    objectStatusDeleteSkipped,
+			},
+		},
+		"avoid deletion because of untouched objects": {
+			client: &fake.RESTClient{
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+					return nil, errors.New("no calls are expected here")
+				}),
+			},
+			startingObjects: []*unstructured.Unstructured{deployment, service},
+			currentStatus: map[*unstructured.Unstructured]objectStatus{
+				deployment: objectStatusDeleteSuccessfull,
+			},
+		},
 		"failed deletion": {
 			client: &fake.RESTClient{
 				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
@@ -377,7 +483,7 @@ func TestDeleteConfigMap(t *testing.T) {
 
 			factory := pkgtesting.NewTestClientFactory()
 			factory.Client = testCase.client
-			manager := NewManager(testInventory(t, factory), nil)
+			manager := NewManager(testInventory(t, factory), testCase.startingObjects)
 			manager.objectStatuses = testCase.currentStatus
 			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
 			defer cancel()
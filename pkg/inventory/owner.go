@@ -0,0 +1,101 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// OwningInventoryAnnotation is set on every applied object with the identifier of the inventory that owns it
+	OwningInventoryAnnotation = "config.k8s.io/owning-inventory"
+)
+
+// AdoptionPolicy determine how to handle objects that already exist on the remote server but are not owned by
+// the current inventory
+type AdoptionPolicy int
+
+const (
+	// AdoptIfUnowned will take ownership of the objects that are not owned by any inventory, and fail for
+	// objects owned by another one
+	AdoptIfUnowned AdoptionPolicy = iota
+	// AdoptAlways will take ownership of the objects regardless of their current owner
+	AdoptAlways
+	// AdoptNever will fail for every object that is not already owned by the current inventory
+	AdoptNever
+)
+
+// OwnershipError is returned when an object cannot be modified by the current inventory because it is owned
+// by someone else
+type OwnershipError struct {
+	// Owner is the current owner of the object, empty if it's not owned by any inventory
+	Owner string
+	// Inventory is the identifier of the current inventory
+	Inventory string
+}
+
+func (e *OwnershipError) Error() string {
+	if e.Owner == "" {
+		return fmt.Sprintf("object is not owned by inventory %q", e.Inventory)
+	}
+	return fmt.Sprintf("object is owned by inventory %q instead of %q", e.Owner, e.Inventory)
+}
+
+// OwnerOf return the identifier of the inventory that owns obj, or an empty string if it is not owned by anyone
+func OwnerOf(obj *unstructured.Unstructured) string {
+	return obj.GetAnnotations()[OwningInventoryAnnotation]
+}
+
+// SetOwner will stamp obj with the inventoryID as its owner
+func SetOwner(obj *unstructured.Unstructured, inventoryID string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+
+	annotations[OwningInventoryAnnotation] = inventoryID
+	obj.SetAnnotations(annotations)
+}
+
+// CanAdopt return an OwnershipError if the live object cannot be owned by the inventoryID following the policy.
+// A nil live object is always adoptable because it has yet to be created.
+func CanAdopt(live *unstructured.Unstructured, inventoryID string, policy AdoptionPolicy) error {
+	if live == nil {
+		return nil
+	}
+
+	owner := OwnerOf(live)
+	switch {
+	case owner == inventoryID, policy == AdoptAlways:
+		return nil
+	case owner == "" && policy == AdoptIfUnowned:
+		return nil
+	default:
+		return &OwnershipError{Owner: owner, Inventory: inventoryID}
+	}
+}
+
+// CanPrune return an OwnershipError if the live object is owned by an inventory different from inventoryID.
+// Objects without an owner can be pruned for keeping compatibility with inventories that predate ownership.
+func CanPrune(live *unstructured.Unstructured, inventoryID string) error {
+	if owner := OwnerOf(live); owner != "" && owner != inventoryID {
+		return &OwnershipError{Owner: owner, Inventory: inventoryID}
+	}
+
+	return nil
+}
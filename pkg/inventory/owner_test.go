@@ -0,0 +1,121 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetOwner(t *testing.T) {
+	t.Parallel()
+
+	obj := &unstructured.Unstructured{}
+	assert.Empty(t, OwnerOf(obj))
+
+	SetOwner(obj, "test/inventory")
+	assert.Equal(t, "test/inventory", OwnerOf(obj))
+
+	obj.SetAnnotations(map[string]string{"other": "value"})
+	SetOwner(obj, "test/other")
+	assert.Equal(t, map[string]string{"other": "value", OwningInventoryAnnotation: "test/other"}, obj.GetAnnotations())
+}
+
+func TestIDOf(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "test/inventory", IDOf(&configMapStore{namespace: "test", name: "inventory"}))
+	assert.Empty(t, IDOf(anonymousStore{}))
+}
+
+func TestCanAdopt(t *testing.T) {
+	t.Parallel()
+
+	ownedObject := func(owner string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		if owner != "" {
+			SetOwner(obj, owner)
+		}
+		return obj
+	}
+
+	testCases := map[string]struct {
+		live          *unstructured.Unstructured
+		policy        AdoptionPolicy
+		expectedError string
+	}{
+		"missing object is always adoptable": {
+			policy: AdoptNever,
+		},
+		"object owned by the inventory": {
+			live:   ownedObject("test/inventory"),
+			policy: AdoptNever,
+		},
+		"unowned object with adopt if unowned": {
+			live:   ownedObject(""),
+			policy: AdoptIfUnowned,
+		},
+		"unowned object with adopt never": {
+			live:          ownedObject(""),
+			policy:        AdoptNever,
+			expectedError: `object is not owned by inventory "test/inventory"`,
+		},
+		"object owned by another inventory with adopt if unowned": {
+			live:          ownedObject("test/other"),
+			policy:        AdoptIfUnowned,
+			expectedError: `object is owned by inventory "test/other" instead of "test/inventory"`,
+		},
+		"object owned by another inventory with adopt always": {
+			live:   ownedObject("test/other"),
+			policy: AdoptAlways,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			err := CanAdopt(testCase.live, "test/inventory", testCase.policy)
+			if len(testCase.expectedError) > 0 {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCanPrune(t *testing.T) {
+	t.Parallel()
+
+	obj := &unstructured.Unstructured{}
+	assert.NoError(t, CanPrune(obj, "test/inventory"))
+
+	SetOwner(obj, "test/inventory")
+	assert.NoError(t, CanPrune(obj, "test/inventory"))
+
+	SetOwner(obj, "test/other")
+	var ownershipErr *OwnershipError
+	assert.ErrorAs(t, CanPrune(obj, "test/inventory"), &ownershipErr)
+	assert.Equal(t, "test/other", ownershipErr.Owner)
+}
+
+// anonymousStore is a Store that does not implement IdentifiableStore
+type anonymousStore struct {
+	Store
+}
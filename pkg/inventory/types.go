@@ -39,3 +39,22 @@ type Store interface {
 	// SetObjects will replace the current in memory objects inventory data
 	SetObjects(objects sets.Set[*unstructured.Unstructured])
 }
+
+// IdentifiableStore is a Store that has a stable identifier, the objects applied with it are marked with the
+// identifier for tracking their owner and protecting them from other inventories
+type IdentifiableStore interface {
+	Store
+
+	// ID return the identifier of the inventory used for marking the objects that it owns
+	ID() string
+}
+
+// IDOf return the identifier of store if it implements IdentifiableStore, or an empty string that will disable
+// the ownership marking of the objects
+func IDOf(store Store) string {
+	if identifiable, ok := store.(IdentifiableStore); ok {
+		return identifiable.ID()
+	}
+
+	return ""
+}
@@ -21,9 +21,12 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
 )
 
 var _ StatusPoller = &FakePoller{}
+var _ StatusChecker = &FakePoller{}
+var _ DeletionPoller = &FakePoller{}
 
 // FakePoller is used to test correct behaviour of code that will work with events sent from a StatusPoller
 type FakePoller struct{}
@@ -57,3 +60,27 @@ func (p *FakePoller) Start(ctx context.Context, objs []*unstructured.Unstructure
 
 	return eventCh
 }
+
+// Status implement StatusChecker, computing the status using only the default checkers
+func (p *FakePoller) Status(obj *unstructured.Unstructured) (*Result, error) {
+	return statusCheck(obj, nil)
+}
+
+// StartDeletion implement DeletionPoller, it will report all the objs as already removed from the remote server
+func (p *FakePoller) StartDeletion(ctx context.Context, objs []*unstructured.Unstructured) <-chan event.Event {
+	eventCh := make(chan event.Event)
+
+	go func() {
+		defer close(eventCh)
+
+		for _, obj := range objs {
+			if ctx.Err() != nil {
+				break
+			}
+
+			eventCh <- eventFromDeletionResult(notFoundResult(notFoundMessage), resource.ObjectMetadataFromUnstructured(obj))
+		}
+	}()
+
+	return eventCh
+}
@@ -122,6 +122,15 @@ func (i *informer) setWatchErrorHandler(handler cache.WatchErrorHandler) error {
 	return i.sharedInformer.SetWatchErrorHandler(handler)
 }
 
+func (i *informer) hasSynced() bool {
+	return i.sharedInformer.HasSynced()
+}
+
+func (i *informer) getByKey(key string) (bool, error) {
+	_, exists, err := i.sharedInformer.GetStore().GetByKey(key)
+	return exists, err
+}
+
 func (i *informer) addEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
 	return i.sharedInformer.AddEventHandler(handler)
 }
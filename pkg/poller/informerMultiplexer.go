@@ -23,12 +23,14 @@ import (
 	"slices"
 	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 )
 
 // informerMultiplexer can be used to manage multiple informer better than a working group. With informerMultiplexer
@@ -38,6 +40,10 @@ type informerMultiplexer struct {
 	Resources       []informerResource
 	ObjectToObserve []resource.ObjectMetadata
 
+	// WaitDeletion will change the reported statuses for waiting the removal of the observed objects instead of
+	// their reconciliation
+	WaitDeletion bool
+
 	context            context.Context
 	cancelFunc         context.CancelFunc
 	channelMultiplexer *multiplexer[event.Event]
@@ -67,6 +73,10 @@ func (im *informerMultiplexer) Run(ctx context.Context) <-chan event.Event {
 		return errorCh
 	}
 
+	ctx, span := telemetry.StartSpan(ctx, "poller",
+		attribute.Int("jpl.poller.objects", len(im.ObjectToObserve)),
+		attribute.Bool("jpl.poller.wait_deletion", im.WaitDeletion),
+	)
 	im.context, im.cancelFunc = context.WithCancel(ctx)
 	im.channelMultiplexer = newMultiplexer[event.Event](im.context.Done())
 	im.informers = make(map[informerResource]*informer, len(im.Resources))
@@ -78,6 +88,7 @@ func (im *informerMultiplexer) Run(ctx context.Context) <-chan event.Event {
 	// block in another goroutine until the context is marked as done to flip the stopped property to true
 	go func() {
 		<-im.context.Done()
+		span.End()
 
 		im.lock.Lock()
 		defer im.lock.Unlock()
@@ -145,13 +156,58 @@ func (im *informerMultiplexer) runInformer(resource informerResource) (*informer
 		return informer, err
 	}
 
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		informer.Run()
+	}()
+
+	if im.WaitDeletion {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			im.notifyMissingObjects(resource, informer, informerCh)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
 		close(informerCh)
 	}()
 	return informer, nil
 }
 
+// notifyMissingObjects wait for the informer cache to be populated and then send a status for every observed
+// object that is not present in it, because an already removed object will never trigger any informer event
+func (im *informerMultiplexer) notifyMissingObjects(resource informerResource, informer *informer, eventCh chan<- event.Event) {
+	if !cache.WaitForCacheSync(im.context.Done(), informer.hasSynced) {
+		return
+	}
+
+	for _, id := range im.ObjectToObserve {
+		if id.Group != resource.GroupKind.Group || id.Kind != resource.GroupKind.Kind || id.Namespace != resource.Namespace {
+			continue
+		}
+
+		key := id.Name
+		if id.Namespace != "" {
+			key = id.Namespace + "/" + id.Name
+		}
+
+		exists, err := informer.getByKey(key)
+		if err != nil || exists {
+			continue
+		}
+
+		select {
+		case eventCh <- eventFromDeletionResult(notFoundResult(notFoundMessage), id):
+		case <-im.context.Done():
+			return
+		}
+	}
+}
+
 func (im *informerMultiplexer) watchErrorHandler(_ informerResource, eventCh chan<- event.Event, err error) {
 	// TODO: handle the various errors
 	switch {
@@ -190,7 +246,7 @@ func (im *informerMultiplexer) eventHandler(ctx context.Context, eventCh chan<-
 			return
 		}
 
-		eventCh <- eventFromResult(result, unstruct)
+		eventCh <- im.statusEvent(result, unstruct)
 	}
 
 	// we don't care of the old version of the object
@@ -216,7 +272,7 @@ func (im *informerMultiplexer) eventHandler(ctx context.Context, eventCh chan<-
 			return
 		}
 
-		eventCh <- eventFromResult(result, unstruct)
+		eventCh <- im.statusEvent(result, unstruct)
 	}
 
 	handler.DeleteFunc = func(obj interface{}) {
@@ -240,7 +296,12 @@ func (im *informerMultiplexer) eventHandler(ctx context.Context, eventCh chan<-
 			return
 		}
 
-		eventCh <- eventFromResult(terminatingResult(deletionMessage), unstruct)
+		if im.WaitDeletion {
+			eventCh <- im.statusEvent(notFoundResult(notFoundMessage), unstruct)
+			return
+		}
+
+		eventCh <- im.statusEvent(terminatingResult(deletionMessage), unstruct)
 	}
 
 	return handler
@@ -250,6 +311,15 @@ func (im *informerMultiplexer) filterObject(obj *unstructured.Unstructured) bool
 	return !slices.Contains(im.ObjectToObserve, resource.ObjectMetadataFromUnstructured(obj))
 }
 
+// statusEvent return the event to send for result based on what the multiplexer is waiting for
+func (im *informerMultiplexer) statusEvent(result *Result, obj *unstructured.Unstructured) event.Event {
+	if im.WaitDeletion {
+		return eventFromDeletionResult(result, resource.ObjectMetadataFromUnstructured(obj))
+	}
+
+	return eventFromResult(result, obj)
+}
+
 func eventFromResult(result *Result, obj *unstructured.Unstructured) event.Event {
 	statusEvent := event.Event{
 		Type: event.TypeStatusUpdate,
@@ -265,6 +335,29 @@ func eventFromResult(result *Result, obj *unstructured.Unstructured) event.Event
 		statusEvent.StatusUpdateInfo.Status = event.StatusPending
 	case StatusFailed:
 		statusEvent.StatusUpdateInfo.Status = event.StatusFailed
+		statusEvent.StatusUpdateInfo.Reason = result.Reason
+		if result.Reason == event.ReasonNone {
+			statusEvent.StatusUpdateInfo.Reason = event.ReasonUnknown
+		}
+	}
+
+	return statusEvent
+}
+
+// eventFromDeletionResult return a status event for an object that is expected to be removed, the only successful
+// status is its disappearance from the remote server, every other one means that we have to keep waiting
+func eventFromDeletionResult(result *Result, id resource.ObjectMetadata) event.Event {
+	statusEvent := event.Event{
+		Type: event.TypeStatusUpdate,
+		StatusUpdateInfo: event.StatusUpdateInfo{
+			Status:         event.StatusPending,
+			Message:        result.Message,
+			ObjectMetadata: id,
+		},
+	}
+
+	if result.Status == StatusNotFound {
+		statusEvent.StatusUpdateInfo.Status = event.StatusSuccessful
 	}
 
 	return statusEvent
@@ -41,6 +41,27 @@ type StatusPoller interface {
 	Start(context.Context, []*unstructured.Unstructured) <-chan event.Event
 }
 
+// DeletionPoller can be implemented by a StatusPoller for waiting the removal of resources from the remote
+// api-server, without it the deletions are not awaited
+type DeletionPoller interface {
+	// StartDeletion will start polling the remote api-server waiting for the passed resources to be removed, a
+	// resource is reported as successful only when it cannot be found anymore
+	StartDeletion(context.Context, []*unstructured.Unstructured) <-chan event.Event
+}
+
+// StatusChecker can be implemented by a StatusPoller for computing the status of an object from its current state,
+// without making any request to the remote api-server
+type StatusChecker interface {
+	// Status return the status Result for the given object
+	Status(*unstructured.Unstructured) (*Result, error)
+}
+
+// keep it to always check if defaultStatusPoller implement correctly the StatusPoller, StatusChecker and
+// DeletionPoller interfaces
+var _ StatusPoller = &defaultStatusPoller{}
+var _ StatusChecker = &defaultStatusPoller{}
+var _ DeletionPoller = &defaultStatusPoller{}
+
 type defaultStatusPoller struct {
 	client         dynamic.Interface
 	mapper         meta.RESTMapper
@@ -72,6 +93,25 @@ func (p *defaultStatusPoller) Start(ctx context.Context, objects []*unstructured
 	return multiplexer.Run(ctx)
 }
 
+// StartDeletion implement DeletionPoller interface
+func (p *defaultStatusPoller) StartDeletion(ctx context.Context, objects []*unstructured.Unstructured) <-chan event.Event {
+	informerResources, ids := resourcesAndIDsFromObjects(objects)
+	multiplexer := &informerMultiplexer{
+		InformerBuilder:      *newInfromerBuilder(p.client, p.mapper, p.resync),
+		Resources:            informerResources,
+		ObjectToObserve:      ids,
+		WaitDeletion:         true,
+		customStatusCheckers: p.statusCheckers,
+	}
+
+	return multiplexer.Run(ctx)
+}
+
+// Status implement StatusChecker interface
+func (p *defaultStatusPoller) Status(object *unstructured.Unstructured) (*Result, error) {
+	return statusCheck(object, p.statusCheckers)
+}
+
 // resourcesAndIDsFromObjects return an array of unique InformerResources created from objects
 func resourcesAndIDsFromObjects(objects []*unstructured.Unstructured) ([]informerResource, []resource.ObjectMetadata) {
 	results := make(sets.Set[informerResource], 0)
@@ -200,6 +200,7 @@ func TestPoller(t *testing.T) {
 						Status:         event.StatusFailed,
 						Message:        "custom message",
 						ObjectMetadata: resource.ObjectMetadataFromUnstructured(crd),
+						Reason:         event.ReasonUnknown,
 					},
 				},
 			},
@@ -316,6 +317,125 @@ func TestPoller(t *testing.T) {
 	}
 }
 
+func TestPollerDeletion(t *testing.T) {
+	t.Parallel()
+
+	testdata := "testdata"
+	deployNamespace := "test-poller-deletion"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployCurrent.yaml"))
+	deployment.SetNamespace(deployNamespace)
+	deplyGVK := deployment.GroupVersionKind()
+
+	mapper := fakeRESTMapper(deplyGVK)
+	deployMapping, err := mapper.RESTMapping(deplyGVK.GroupKind(), deplyGVK.Version)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		remoteObjects  []*unstructured.Unstructured
+		expectedEvents []event.Event
+		updates        []func(*dynamicfake.FakeDynamicClient)
+	}{
+		"object already removed": {
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusSuccessful,
+						Message:        notFoundMessage,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+			},
+			updates: []func(*dynamicfake.FakeDynamicClient){
+				func(*dynamicfake.FakeDynamicClient) {},
+			},
+		},
+		"wait object removal": {
+			remoteObjects: []*unstructured.Unstructured{
+				deployment,
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusPending,
+						Message:        fmt.Sprintf(deploymentCurrentMessageFormat, 1),
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+				{
+					Type: event.TypeStatusUpdate,
+					StatusUpdateInfo: event.StatusUpdateInfo{
+						Status:         event.StatusSuccessful,
+						Message:        notFoundMessage,
+						ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+					},
+				},
+			},
+			updates: []func(*dynamicfake.FakeDynamicClient){
+				func(*dynamicfake.FakeDynamicClient) {},
+				func(client *dynamicfake.FakeDynamicClient) {
+					require.NoError(t, client.Tracker().Delete(deployMapping.Resource, deployNamespace, deployment.GetName()))
+				},
+			},
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+
+			client := dynamicfake.NewSimpleDynamicClient(pkgtesting.Scheme)
+			for _, obj := range testCase.remoteObjects {
+				require.NoError(t, client.Tracker().Create(deployMapping.Resource, obj, obj.GetNamespace()))
+			}
+
+			poller, ok := NewDefaultStatusPoller(client, mapper, nil).(DeletionPoller)
+			require.True(t, ok)
+			eventCh := poller.StartDeletion(ctx, []*unstructured.Unstructured{deployment})
+			time.Sleep(150 * time.Millisecond) // Allow the pollers to start
+
+			steppingCh := make(chan struct{})
+			defer close(steppingCh)
+
+			go func() {
+				<-steppingCh
+				for _, update := range testCase.updates {
+					update(client)
+					<-steppingCh
+				}
+				cancel()
+			}()
+
+			steppingCh <- struct{}{}
+
+			receivedEvents := make([]event.Event, 0)
+		loop:
+			for {
+				select {
+				case event, open := <-eventCh:
+					if !open {
+						break loop
+					}
+					t.Log(event)
+					receivedEvents = append(receivedEvents, event)
+					steppingCh <- struct{}{}
+
+				case <-ctx.Done():
+					break loop
+				}
+			}
+
+			require.NotEqual(t, context.DeadlineExceeded, ctx.Err())
+			assert.Equal(t, testCase.expectedEvents, receivedEvents)
+		})
+	}
+}
+
 func TestPollerErrors(t *testing.T) {
 	t.Parallel()
 
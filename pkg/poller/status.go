@@ -15,6 +15,8 @@
 
 package poller
 
+import "github.com/mia-platform/jpl/pkg/event"
+
 //go:generate ${TOOLS_BIN}/stringer -type=Status -trimprefix=Status
 type Status int
 
@@ -23,11 +25,14 @@ const (
 	StatusFailed
 	StatusTerminating
 	StatusCurrent
+	StatusNotFound
 )
 
 type Result struct {
 	Status  Status
 	Message string
+	// Reason classify the failure of a StatusFailed result, if not set the failure is reported as event.ReasonUnknown
+	Reason event.Reason
 }
 
 func currentResult(message string) *Result {
@@ -51,9 +56,24 @@ func terminatingResult(message string) *Result {
 	}
 }
 
+func notFoundResult(message string) *Result {
+	return &Result{
+		Status:  StatusNotFound,
+		Message: message,
+	}
+}
+
 func failedResult(message string) *Result {
 	return &Result{
 		Status:  StatusFailed,
 		Message: message,
 	}
 }
+
+func timedOutResult(message string) *Result {
+	return &Result{
+		Status:  StatusFailed,
+		Message: message,
+		Reason:  event.ReasonTimeout,
+	}
+}
@@ -12,11 +12,12 @@ func _() {
 	_ = x[StatusFailed-1]
 	_ = x[StatusTerminating-2]
 	_ = x[StatusCurrent-3]
+	_ = x[StatusNotFound-4]
 }
 
-const _Status_name = "InProgressFailedTerminatingCurrent"
+const _Status_name = "InProgressFailedTerminatingCurrentNotFound"
 
-var _Status_index = [...]uint8{0, 10, 16, 27, 34}
+var _Status_index = [...]uint8{0, 10, 16, 27, 34, 42}
 
 func (i Status) String() string {
 	idx := int(i) - 0
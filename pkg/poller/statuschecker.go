@@ -33,6 +33,7 @@ import (
 const (
 	generationMessageFormat = "%q current generation is %d, observed generation is %d"
 	deletionMessage         = "Resource is scheduled for deletion"
+	notFoundMessage         = "Resource has been removed"
 	currentMessage          = "Resource is current"
 
 	crdInProgressMessage = "CRD installation in progress"
@@ -310,6 +311,9 @@ func jobStatusCheck(object *unstructured.Unstructured) (*Result, error) {
 	for _, condition := range job.Status.Conditions {
 		switch {
 		case condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue:
+			if condition.Reason == batchv1.JobReasonDeadlineExceeded {
+				return timedOutResult(condition.Message), nil
+			}
 			return failedResult(condition.Message), nil
 		case condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue:
 			message := fmt.Sprintf(jobCurrentMessageFormat, succeeded, completions)
@@ -409,7 +413,7 @@ func deployStatusCheck(object *unstructured.Unstructured) (*Result, error) {
 	for _, condition := range deploy.Status.Conditions {
 		switch {
 		case condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded":
-			return failedResult(deploymentDeadlineMessage), nil
+			return timedOutResult(deploymentDeadlineMessage), nil
 		case condition.Type == appsv1.DeploymentProgressing && condition.Reason == "NewReplicaSetAvailable" && condition.Status == corev1.ConditionTrue:
 			deployIsProgressing = true
 		case condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionTrue:
@@ -131,6 +131,10 @@ func TestStatusCheck(t *testing.T) {
 			object:         pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "jobFailed.yaml")),
 			expectedResult: failedResult("custom message"),
 		},
+		"job with deadline exceeded is failed": {
+			object:         pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "jobDeadlineExceeded.yaml")),
+			expectedResult: timedOutResult("Job was active longer than specified deadline"),
+		},
 		"job with suspended condition is current": {
 			object:         pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "jobSuspended.yaml")),
 			expectedResult: currentResult(jobSuspendedMessage),
@@ -197,7 +201,7 @@ func TestStatusCheck(t *testing.T) {
 		},
 		"deployment with deadline exceeded is failed": {
 			object:         pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployDeadlineExceeded.yaml")),
-			expectedResult: failedResult(deploymentDeadlineMessage),
+			expectedResult: timedOutResult(deploymentDeadlineMessage),
 		},
 		"deployment with less updating replicas is in progress": {
 			object:         pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployUpdating.yaml")),
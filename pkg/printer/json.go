@@ -0,0 +1,60 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+// keep it to always check if jsonPrinter implement correctly the Interface
+var _ Interface = &jsonPrinter{}
+
+type jsonPrinter struct {
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewJSONPrinter return a printer that will write every event to w as a Record in JSON format on a single line
+func NewJSONPrinter(w io.Writer) Interface {
+	return &jsonPrinter{
+		writer: w,
+		now:    time.Now,
+	}
+}
+
+// Print implement Interface
+func (p *jsonPrinter) Print(ch <-chan event.Event) error {
+	encoder := json.NewEncoder(p.writer)
+	for e := range ch {
+		if err := encoder.Encode(NewRecord(e, p.now())); err != nil {
+			drain(ch)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drain consume all the remaining events in ch for not blocking its producer
+func drain(ch <-chan event.Event) {
+	for range ch {
+		// discard the event
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+func TestJSONPrinter(t *testing.T) {
+	t.Parallel()
+
+	deployment := testObject("apps/v1", "Deployment", "test", "nginx")
+	buffer := new(bytes.Buffer)
+	printer := &jsonPrinter{writer: buffer, now: fakeClock()}
+
+	err := printer.Print(eventsChannel(
+		event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusPending}},
+		event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusSuccessful}},
+		event.Event{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusSuccessful}},
+	))
+	require.NoError(t, err)
+
+	expected := `{"timestamp":"2026-03-02T10:30:00Z","type":"apply","object":{"group":"apps","kind":"Deployment","namespace":"test","name":"nginx"},"status":"pending","message":"Deployment.apps nginx: apply started..."}
+{"timestamp":"2026-03-02T10:30:01Z","type":"apply","object":{"group":"apps","kind":"Deployment","namespace":"test","name":"nginx"},"status":"successful","message":"Deployment.apps nginx: applied successfully"}
+{"timestamp":"2026-03-02T10:30:02Z","type":"inventory","status":"successful","message":"inventory: applied successfully"}
+`
+	assert.Equal(t, expected, buffer.String())
+}
@@ -0,0 +1,128 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+const (
+	junitSuiteName = "jpl"
+	junitRunName   = "run"
+)
+
+// keep it to always check if junitPrinter implement correctly the Interface
+var _ Interface = &junitPrinter{}
+
+type junitPrinter struct {
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewJUnitPrinter return a printer that will wait for all the events and then write to w a JUnit XML report with
+// a test case for every object, failed and skipped objects are reported as failures and skipped test cases, while
+// the errors not related to an object are reported as errors of a single test case for the run
+func NewJUnitPrinter(w io.Writer) Interface {
+	return &junitPrinter{
+		writer: w,
+		now:    time.Now,
+	}
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// Print implement Interface
+func (p *junitPrinter) Print(ch <-chan event.Event) error {
+	s := newSummary(ch, p.now)
+
+	suite := junitTestSuite{
+		Name:      junitSuiteName,
+		Time:      fmt.Sprintf("%.3f", s.end.Sub(s.start).Seconds()),
+		Timestamp: s.start.UTC().Format(time.RFC3339),
+		TestCases: make([]junitTestCase, 0, len(s.objects)+1),
+	}
+
+	for _, result := range s.objects {
+		testCase := junitTestCase{
+			Name:      result.object.String(),
+			ClassName: result.action,
+		}
+
+		switch result.status {
+		case statusNames[event.StatusFailed]:
+			testCase.Failure = &junitMessage{Message: result.message}
+			suite.Failures++
+		case statusNames[event.StatusSkipped]:
+			testCase.Skipped = &junitMessage{Message: result.message}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	for _, err := range s.errors {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      junitRunName,
+			ClassName: junitSuiteName,
+			Error:     &junitMessage{Message: err},
+		})
+		suite.Errors++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(p.writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(p.writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(p.writer, "\n")
+	return err
+}
@@ -0,0 +1,51 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitPrinter(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	printer := &junitPrinter{writer: buffer, now: fakeClock()}
+	require.NoError(t, printer.Print(eventsChannel(runEvents()...)))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="jpl" tests="5" failures="1" errors="1" skipped="1" time="11.000" timestamp="2026-03-02T10:30:00Z">
+    <testcase name="Deployment.apps test/nginx" classname="apply"></testcase>
+    <testcase name="Service test/nginx" classname="apply">
+      <failure message="invalid"></failure>
+    </testcase>
+    <testcase name="Job.batch test/migration" classname="apply">
+      <skipped message="dependency failed: Service test/nginx"></skipped>
+    </testcase>
+    <testcase name="Namespace old" classname="prune"></testcase>
+    <testcase name="run" classname="jpl">
+      <error message="inventory: failed to apply: forbidden"></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buffer.String())
+}
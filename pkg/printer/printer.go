@@ -0,0 +1,276 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+// Interface defines the interface for a printer that will render all the events received from a channel
+type Interface interface {
+	// Print consume the events until the channel is closed, return an error only if the events cannot be written
+	Print(<-chan event.Event) error
+}
+
+var (
+	typeNames = map[event.Type]string{
+		event.TypeError:        "error",
+		event.TypeQueue:        "queue",
+		event.TypeApply:        "apply",
+		event.TypePrune:        "prune",
+		event.TypeInventory:    "inventory",
+		event.TypeStatusUpdate: "status",
+		event.TypeDiff:         "diff",
+		event.TypeReplace:      "replace",
+		event.TypeTask:         "task",
+		event.TypeWarning:      "warning",
+		event.TypeRetry:        "retry",
+	}
+
+	statusNames = map[event.Status]string{
+		event.StatusPending:    "pending",
+		event.StatusSuccessful: "successful",
+		event.StatusFailed:     "failed",
+		event.StatusSkipped:    "skipped",
+	}
+
+	diffActionNames = map[event.DiffAction]string{
+		event.DiffCreate:    "create",
+		event.DiffUpdate:    "update",
+		event.DiffUnchanged: "unchanged",
+		event.DiffPrune:     "prune",
+		event.DiffFailed:    "failed",
+		event.DiffSkipped:   "skipped",
+	}
+
+	applyOperationNames = map[event.ApplyOperation]string{
+		event.ApplyCreated:    "created",
+		event.ApplyConfigured: "configured",
+		event.ApplyUnchanged:  "unchanged",
+	}
+
+	reasonNames = map[event.Reason]string{
+		event.ReasonUnknown:       "unknown",
+		event.ReasonForbidden:     "forbidden",
+		event.ReasonInvalid:       "invalid",
+		event.ReasonConflict:      "conflict",
+		event.ReasonNotFound:      "notFound",
+		event.ReasonTimeout:       "timeout",
+		event.ReasonWebhookDenied: "webhookDenied",
+		event.ReasonQuotaExceeded: "quotaExceeded",
+		event.ReasonUnavailable:   "unavailable",
+	}
+)
+
+// Record is the representation of an event with stable field names that can be serialized
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	// Object identify the object of the event, is empty for events that are not related to a single object
+	Object *ObjectReference `json:"object,omitempty"`
+	// Objects contains all the objects handled by a queue event
+	Objects []ObjectReference `json:"objects,omitempty"`
+	// Action is the change that will be made to the object in a diff event, or the one that has been made by
+	// a successful apply event
+	Action  string `json:"action,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+	// Reason classify the failure reported by an apply, prune or status update event
+	Reason string `json:"reason,omitempty"`
+	// Causes contains the field level causes of a failure returned by the api-server
+	Causes []CauseRecord `json:"causes,omitempty"`
+	// Diff contains the changes in unified format of a diff event
+	Diff string `json:"diff,omitempty"`
+	// Conflicts contains the fields owned by other field managers that have blocked an apply
+	Conflicts []ConflictRecord `json:"conflicts,omitempty"`
+	// Task contains the details of a task lifecycle event
+	Task *TaskRecord `json:"task,omitempty"`
+	// Retry contains the details of a retry event
+	Retry *RetryRecord `json:"retry,omitempty"`
+}
+
+// ObjectReference identify an object inside a Record
+type ObjectReference struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// String return the object identifier as group kind followed by its namespaced name
+func (r ObjectReference) String() string {
+	gk := r.Kind
+	if r.Group != "" {
+		gk += "." + r.Group
+	}
+
+	if r.Namespace == "" {
+		return gk + " " + r.Name
+	}
+	return gk + " " + r.Namespace + "/" + r.Name
+}
+
+// ConflictRecord is the representation of an event.Conflict inside a Record
+type ConflictRecord struct {
+	Field   string `json:"field"`
+	Manager string `json:"manager,omitempty"`
+	Message string `json:"message"`
+}
+
+// CauseRecord is the representation of an event.Cause inside a Record
+type CauseRecord struct {
+	Type    string `json:"type,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// TaskRecord is the representation of an event.TaskInfo inside a Record
+type TaskRecord struct {
+	Kind    string `json:"kind"`
+	Group   int    `json:"group"`
+	Objects int    `json:"objects"`
+	// DurationSeconds is the time taken by the task, set only when it has finished
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+// RetryRecord is the representation of an event.RetryInfo inside a Record
+type RetryRecord struct {
+	Operation    string  `json:"operation"`
+	Attempt      int     `json:"attempt"`
+	MaxAttempts  int     `json:"maxAttempts"`
+	DelaySeconds float64 `json:"delaySeconds"`
+}
+
+// NewRecord return the Record for e, timestamp is used only if the event does not carry its own
+func NewRecord(e event.Event, timestamp time.Time) Record {
+	if !e.Timestamp.IsZero() {
+		timestamp = e.Timestamp
+	}
+
+	record := Record{
+		Timestamp: timestamp.UTC(),
+		Type:      typeNames[e.Type],
+		Message:   e.String(),
+	}
+
+	switch e.Type {
+	case event.TypeError:
+		record.Error = errorString(e.ErrorInfo.Error)
+	case event.TypeQueue:
+		for _, obj := range e.QueueInfo.Objects {
+			record.Objects = append(record.Objects, objectReference(obj))
+		}
+	case event.TypeApply:
+		record.Object = objectReferencePointer(e.ApplyInfo.Object)
+		record.Status = statusNames[e.ApplyInfo.Status]
+		record.Error = errorString(e.ApplyInfo.Error)
+		record.Action = applyOperationNames[e.ApplyInfo.Operation]
+		for _, conflict := range e.ApplyInfo.Conflicts {
+			record.Conflicts = append(record.Conflicts, ConflictRecord(conflict))
+		}
+		record.Reason = reasonNames[e.ApplyInfo.Reason]
+		record.Causes = causeRecords(e.ApplyInfo.Causes)
+	case event.TypePrune:
+		record.Object = objectReferencePointer(e.PruneInfo.Object)
+		record.Status = statusNames[e.PruneInfo.Status]
+		record.Error = errorString(e.PruneInfo.Error)
+		record.Reason = reasonNames[e.PruneInfo.Reason]
+		record.Causes = causeRecords(e.PruneInfo.Causes)
+	case event.TypeReplace:
+		record.Object = objectReferencePointer(e.ReplaceInfo.Object)
+		record.Status = statusNames[e.ReplaceInfo.Status]
+		record.Error = errorString(e.ReplaceInfo.Error)
+	case event.TypeInventory:
+		record.Status = statusNames[e.InventoryInfo.Status]
+		record.Error = errorString(e.InventoryInfo.Error)
+	case event.TypeStatusUpdate:
+		reference := objectReferenceFromMetadata(e.StatusUpdateInfo.ObjectMetadata)
+		record.Object = &reference
+		record.Status = statusNames[e.StatusUpdateInfo.Status]
+		record.Message = e.StatusUpdateInfo.Message
+		record.Reason = reasonNames[e.StatusUpdateInfo.Reason]
+	case event.TypeDiff:
+		record.Object = objectReferencePointer(e.DiffInfo.Object)
+		record.Action = diffActionNames[e.DiffInfo.Action]
+		record.Diff = e.DiffInfo.Diff
+		record.Error = errorString(e.DiffInfo.Error)
+	case event.TypeWarning:
+		record.Object = objectReferencePointer(e.WarningInfo.Object)
+	case event.TypeRetry:
+		record.Object = objectReferencePointer(e.RetryInfo.Object)
+		record.Error = errorString(e.RetryInfo.Error)
+		record.Retry = &RetryRecord{
+			Operation:    e.RetryInfo.Operation,
+			Attempt:      e.RetryInfo.Attempt,
+			MaxAttempts:  e.RetryInfo.MaxAttempts,
+			DelaySeconds: e.RetryInfo.Delay.Seconds(),
+		}
+	case event.TypeTask:
+		record.Status = statusNames[e.TaskInfo.Status]
+		record.Task = &TaskRecord{
+			Kind:            e.TaskInfo.Kind,
+			Group:           e.TaskInfo.Group,
+			Objects:         e.TaskInfo.Objects,
+			DurationSeconds: e.TaskInfo.Duration.Seconds(),
+		}
+	}
+
+	return record
+}
+
+// objectReference return the ObjectReference for obj
+func objectReference(obj *unstructured.Unstructured) ObjectReference {
+	id := resource.ObjectMetadataFromUnstructured(obj)
+	return ObjectReference{
+		Group:     id.Group,
+		Kind:      id.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+	}
+}
+
+// objectReferencePointer return a pointer to the ObjectReference for obj, or nil if obj is nil
+func objectReferencePointer(obj *unstructured.Unstructured) *ObjectReference {
+	if obj == nil {
+		return nil
+	}
+
+	reference := objectReference(obj)
+	return &reference
+}
+
+// errorString return the message of err or an empty string if err is nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// causeRecords return the representation of causes inside a Record
+func causeRecords(causes []event.Cause) []CauseRecord {
+	var records []CauseRecord
+	for _, cause := range causes {
+		records = append(records, CauseRecord(cause))
+	}
+	return records
+}
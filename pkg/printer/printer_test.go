@@ -0,0 +1,263 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+var testTime = time.Date(2026, time.March, 2, 10, 30, 0, 0, time.UTC)
+
+func testObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// fakeClock return a function that will return a time one second after the previous call, starting from testTime
+func fakeClock() func() time.Time {
+	current := testTime.Add(-time.Second)
+	return func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	}
+}
+
+// eventsChannel return a closed channel filled with events
+func eventsChannel(events ...event.Event) <-chan event.Event {
+	ch := make(chan event.Event, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func TestNewRecord(t *testing.T) {
+	t.Parallel()
+
+	deployment := testObject("apps/v1", "Deployment", "test", "nginx")
+	namespace := testObject("v1", "Namespace", "", "test")
+	deploymentReference := &ObjectReference{Group: "apps", Kind: "Deployment", Namespace: "test", Name: "nginx"}
+
+	testCases := map[string]struct {
+		event    event.Event
+		expected Record
+	}{
+		"error event": {
+			event: event.Event{
+				Type:      event.TypeError,
+				ErrorInfo: event.ErrorInfo{Error: errors.New("something went wrong")},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "error",
+				Message:   "something went wrong",
+				Error:     "something went wrong",
+			},
+		},
+		"queue event": {
+			event: event.Event{
+				Type:      event.TypeQueue,
+				QueueInfo: event.QueueInfo{Objects: []*unstructured.Unstructured{namespace, deployment}},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "queue",
+				Objects: []ObjectReference{
+					{Kind: "Namespace", Name: "test"},
+					*deploymentReference,
+				},
+				Message: "queue started for: [Namespace test Deployment.apps nginx]",
+			},
+		},
+		"unchanged apply event": {
+			event: event.Event{
+				Type: event.TypeApply,
+				ApplyInfo: event.ApplyInfo{
+					Object:    deployment,
+					Status:    event.StatusSuccessful,
+					Operation: event.ApplyUnchanged,
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "apply",
+				Object:    deploymentReference,
+				Action:    "unchanged",
+				Status:    "successful",
+				Message:   "Deployment.apps nginx: unchanged",
+			},
+		},
+		"failed apply event with conflicts": {
+			event: event.Event{
+				Type: event.TypeApply,
+				ApplyInfo: event.ApplyInfo{
+					Object: deployment,
+					Status: event.StatusFailed,
+					Error:  errors.New("conflict"),
+					Conflicts: []event.Conflict{
+						{Field: ".spec.replicas", Manager: "operator", Message: "conflict with \"operator\""},
+					},
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "apply",
+				Object:    deploymentReference,
+				Status:    "failed",
+				Message:   "Deployment.apps nginx: failed to apply: conflict",
+				Error:     "conflict",
+				Conflicts: []ConflictRecord{
+					{Field: ".spec.replicas", Manager: "operator", Message: "conflict with \"operator\""},
+				},
+			},
+		},
+		"failed apply event with causes": {
+			event: event.Event{
+				Type: event.TypeApply,
+				ApplyInfo: event.ApplyInfo{
+					Object: deployment,
+					Status: event.StatusFailed,
+					Error:  errors.New("invalid"),
+					Reason: event.ReasonInvalid,
+					Causes: []event.Cause{
+						{Type: "FieldValueRequired", Field: "spec.selector", Message: "Required value"},
+					},
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "apply",
+				Object:    deploymentReference,
+				Status:    "failed",
+				Message:   "Deployment.apps nginx: failed to apply: invalid",
+				Error:     "invalid",
+				Reason:    "invalid",
+				Causes: []CauseRecord{
+					{Type: "FieldValueRequired", Field: "spec.selector", Message: "Required value"},
+				},
+			},
+		},
+		"status update event": {
+			event: event.Event{
+				Type: event.TypeStatusUpdate,
+				StatusUpdateInfo: event.StatusUpdateInfo{
+					Status:         event.StatusSuccessful,
+					Message:        "Deployment is available. Replicas: 1",
+					ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "status",
+				Object:    deploymentReference,
+				Status:    "successful",
+				Message:   "Deployment is available. Replicas: 1",
+			},
+		},
+		"diff event": {
+			event: event.Event{
+				Type: event.TypeDiff,
+				DiffInfo: event.DiffInfo{
+					Object: namespace,
+					Action: event.DiffPrune,
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "diff",
+				Object:    &ObjectReference{Kind: "Namespace", Name: "test"},
+				Action:    "prune",
+				Message:   "Namespace test: will be pruned",
+			},
+		},
+		"warning event": {
+			event: event.Event{
+				Type: event.TypeWarning,
+				WarningInfo: event.WarningInfo{
+					Object:  namespace,
+					Message: "deprecated api version",
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "warning",
+				Object:    &ObjectReference{Kind: "Namespace", Name: "test"},
+				Message:   "Namespace test: warning: deprecated api version",
+			},
+		},
+		"retry event": {
+			event: event.Event{
+				Type: event.TypeRetry,
+				RetryInfo: event.RetryInfo{
+					Object:      deployment,
+					Operation:   "apply",
+					Attempt:     1,
+					MaxAttempts: 3,
+					Delay:       500 * time.Millisecond,
+					Error:       errors.New("too many requests"),
+				},
+			},
+			expected: Record{
+				Timestamp: testTime,
+				Type:      "retry",
+				Object:    deploymentReference,
+				Message:   "Deployment.apps nginx: apply attempt 1 of 3 failed, retrying in 500ms: too many requests",
+				Error:     "too many requests",
+				Retry:     &RetryRecord{Operation: "apply", Attempt: 1, MaxAttempts: 3, DelaySeconds: 0.5},
+			},
+		},
+		"finished task event with its own timestamp": {
+			event: event.Event{
+				Type:      event.TypeTask,
+				Timestamp: testTime.Add(time.Minute),
+				TaskInfo: event.TaskInfo{
+					Kind:     "apply",
+					Group:    1,
+					Objects:  2,
+					Status:   event.StatusSuccessful,
+					Duration: 1500 * time.Millisecond,
+				},
+			},
+			expected: Record{
+				Timestamp: testTime.Add(time.Minute),
+				Type:      "task",
+				Status:    "successful",
+				Message:   "apply task 1 (2 objects): finished in 1.5s",
+				Task:      &TaskRecord{Kind: "apply", Group: 1, Objects: 2, DurationSeconds: 1.5},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, NewRecord(testCase.event, testTime))
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/mia-platform/jpl/pkg/client"
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+const (
+	defaultRefreshInterval = 100 * time.Millisecond
+
+	// ansi sequences for moving the cursor up of n lines and clearing the screen from the cursor to the end
+	cursorUpSequence      = "\x1b[%dA"
+	clearToScreenEnd      = "\x1b[J"
+	progressSuccessIcon   = "✓"
+	progressFailureIcon   = "✗"
+	progressSkippedIcon   = "-"
+	progressHeader        = "  OBJECT\tAPPLY\tWAIT\tPRUNE\tELAPSED\tMESSAGE"
+	progressSummaryFormat = "%d objects: %d successful, %d failed, %d skipped"
+	progressHiddenFormat  = "  ... and %d more objects"
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// keep it to always check if progressPrinter implement correctly the Interface
+var _ Interface = &progressPrinter{}
+
+type progressPrinter struct {
+	writer          io.Writer
+	now             func() time.Time
+	interactive     bool
+	refreshInterval time.Duration
+	// height return the number of lines of the terminal, zero or less if it is unknown
+	height func() int
+
+	renderedLines int
+	frame         int
+}
+
+// NewProgressPrinter return a printer that will keep updated a table with the progress of every object while
+// the events are received, and will print a final summary when the channel is closed. If w is not a terminal
+// every event is printed on its own line, omitting the status updates that are not changed.
+func NewProgressPrinter(w io.Writer) Interface {
+	return &progressPrinter{
+		writer:          w,
+		now:             time.Now,
+		interactive:     isTerminal(w),
+		refreshInterval: defaultRefreshInterval,
+		height:          terminalHeight(w),
+	}
+}
+
+// isTerminal return true if w is a file connected to a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// terminalHeight return a function that read the current height of the terminal connected to w, it is read
+// on every redraw for following the resizes of the terminal
+func terminalHeight(w io.Writer) func() int {
+	return func() int {
+		file, ok := w.(*os.File)
+		if !ok {
+			return 0
+		}
+
+		_, height, err := term.GetSize(int(file.Fd()))
+		if err != nil {
+			return 0
+		}
+		return height
+	}
+}
+
+// Print implement Interface
+func (p *progressPrinter) Print(ch <-chan event.Event) error {
+	if !p.interactive {
+		return p.printLines(ch)
+	}
+
+	state := newProgressState()
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, open := <-ch:
+			if !open {
+				return p.render(state, true)
+			}
+			state.collector.Add(e)
+			state.add(NewRecord(e, p.now()))
+		case <-ticker.C:
+			p.frame++
+			if err := p.render(state, false); err != nil {
+				drain(ch)
+				return err
+			}
+		}
+	}
+}
+
+// printLines write every event on its own line, skipping the status updates equal to the last one of their object
+func (p *progressPrinter) printLines(ch <-chan event.Event) error {
+	lastStatuses := make(map[ObjectReference]string)
+	for e := range ch {
+		if e.Type == event.TypeStatusUpdate {
+			record := NewRecord(e, p.now())
+			status := record.Status + record.Message
+			if lastStatuses[*record.Object] == status {
+				continue
+			}
+			lastStatuses[*record.Object] = status
+		}
+
+		if _, err := fmt.Fprintln(p.writer, e.String()); err != nil {
+			drain(ch)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render redraw the progress table over the previous one, if final the summary is printed at the end.
+// The cursor cannot be moved above the top of the terminal, so until the final render the table is limited to
+// its height for being able to redraw it in place.
+func (p *progressPrinter) render(state *progressState, final bool) error {
+	buffer := new(bytes.Buffer)
+	if p.renderedLines > 0 {
+		fmt.Fprintf(buffer, cursorUpSequence, p.renderedLines)
+	}
+	buffer.WriteString(clearToScreenEnd)
+
+	maxLines := 0
+	if !final && p.height != nil {
+		// the last line of the terminal is kept for the cursor
+		maxLines = max(p.height()-1, 1)
+	}
+
+	lines := state.lines(p.now(), spinnerFrames[p.frame%len(spinnerFrames)], maxLines)
+	if final {
+		lines = append(lines, "", state.summary())
+		lines = append(lines, state.warnings...)
+		for _, err := range state.errors {
+			lines = append(lines, "error: "+err)
+		}
+	}
+
+	for _, line := range lines {
+		buffer.WriteString(line)
+		buffer.WriteString("\n")
+	}
+
+	p.renderedLines = len(lines)
+	_, err := p.writer.Write(buffer.Bytes())
+	return err
+}
+
+// progressRow contains the progress of a single object
+type progressRow struct {
+	object  ObjectReference
+	apply   string
+	wait    string
+	prune   string
+	message string
+	// lastStatus is the status of the last event received for the object
+	lastStatus string
+
+	start time.Time
+	end   time.Time
+}
+
+// progressState contains the progress of all the objects found in the events
+type progressState struct {
+	rows      []*progressRow
+	rowsIndex map[ObjectReference]*progressRow
+	// collector compute the final outcome of the objects for the summary
+	collector *client.ResultCollector
+
+	inventory string
+	warnings  []string
+	errors    []string
+}
+
+func newProgressState() *progressState {
+	return &progressState{
+		rowsIndex: make(map[ObjectReference]*progressRow),
+		collector: client.NewResultCollector(),
+	}
+}
+
+// row return the progressRow for obj, creating it if is the first time that is found
+func (s *progressState) row(obj ObjectReference) *progressRow {
+	if row, found := s.rowsIndex[obj]; found {
+		return row
+	}
+
+	row := &progressRow{object: obj}
+	s.rowsIndex[obj] = row
+	s.rows = append(s.rows, row)
+	return row
+}
+
+// add update the progress with the content of record
+func (s *progressState) add(record Record) {
+	switch record.Type {
+	case typeNames[event.TypeQueue]:
+		// the queue contains all the objects, so they can be shown before starting to handle them
+		for _, obj := range record.Objects {
+			s.row(obj)
+		}
+		return
+	case typeNames[event.TypeError]:
+		s.errors = append(s.errors, record.Error)
+		return
+	case typeNames[event.TypeWarning]:
+		s.warnings = append(s.warnings, record.Message)
+		return
+	case typeNames[event.TypeRetry]:
+		// a retry only update the message of its object, its status is still the one of the retried request
+		if record.Object != nil {
+			s.row(*record.Object).message = firstLine(record.Message)
+		}
+		return
+	case typeNames[event.TypeInventory]:
+		s.inventory = record.Message
+		return
+	}
+
+	if record.Object == nil {
+		return
+	}
+
+	row := s.row(*record.Object)
+	if row.start.IsZero() {
+		row.start = record.Timestamp
+	}
+	row.end = record.Timestamp
+	row.lastStatus = record.Status
+
+	switch record.Type {
+	case typeNames[event.TypeApply]:
+		row.apply = record.Status
+	case typeNames[event.TypeReplace]:
+		row.apply = record.Type + " " + record.Status
+	case typeNames[event.TypeDiff]:
+		row.apply = record.Action
+	case typeNames[event.TypePrune]:
+		row.prune = record.Status
+	case typeNames[event.TypeStatusUpdate]:
+		row.wait = record.Status
+	}
+
+	row.message = firstLine(record.Message)
+	if record.Error != "" {
+		row.message = record.Error
+	}
+}
+
+// lines return the lines of the progress table, using spinner for the objects still in progress. If maxLines is
+// greater than zero, the rows that do not fit are replaced by a single line with their count, hiding first the
+// objects that have already finished.
+func (s *progressState) lines(now time.Time, spinner string, maxLines int) []string {
+	buffer := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buffer, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+	fmt.Fprintln(tw, progressHeader)
+	for _, row := range s.rows {
+		elapsed := emptyCell
+		if !row.start.IsZero() {
+			end := row.end
+			if row.lastStatus == statusNames[event.StatusPending] {
+				end = now
+			}
+			elapsed = end.Sub(row.start).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\t%s\n",
+			row.icon(spinner),
+			row.object.String(),
+			cell(row.apply),
+			cell(row.wait),
+			cell(row.prune),
+			elapsed,
+			cell(row.message),
+		)
+	}
+	_ = tw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if s.inventory != "" {
+		lines = append(lines, s.inventory)
+	}
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return lines
+	}
+
+	// the header, the line for the hidden rows and the inventory are always shown
+	available := maxLines - 2
+	if s.inventory != "" {
+		available--
+	}
+	visible := s.visibleRows(max(available, 0))
+
+	clamped := []string{lines[0]}
+	for idx := range s.rows {
+		if visible.Has(idx) {
+			clamped = append(clamped, lines[idx+1])
+		}
+	}
+	clamped = append(clamped, fmt.Sprintf(progressHiddenFormat, len(s.rows)-visible.Len()))
+	if s.inventory != "" {
+		clamped = append(clamped, s.inventory)
+	}
+	return clamped
+}
+
+// visibleRows return the indexes of at most count rows to show, preferring the ones that have not finished yet
+func (s *progressState) visibleRows(count int) sets.Set[int] {
+	visible := sets.New[int]()
+	for _, finished := range []bool{false, true} {
+		for idx, row := range s.rows {
+			if visible.Len() == count {
+				return visible
+			}
+			if row.finished() == finished {
+				visible.Insert(idx)
+			}
+		}
+	}
+
+	return visible
+}
+
+// summary return the count of the objects for every final outcome
+func (s *progressState) summary() string {
+	var successful, failed, skipped int
+	objects := s.collector.Result().Objects
+	for _, result := range objects {
+		switch outcomeStatus(result.Outcome) {
+		case statusNames[event.StatusSuccessful]:
+			successful++
+		case statusNames[event.StatusFailed]:
+			failed++
+		case statusNames[event.StatusSkipped]:
+			skipped++
+		}
+	}
+
+	return fmt.Sprintf(progressSummaryFormat, len(objects), successful, failed, skipped)
+}
+
+// finished return true if the object of the row has reached a final status
+func (r *progressRow) finished() bool {
+	switch r.lastStatus {
+	case statusNames[event.StatusSuccessful], statusNames[event.StatusFailed], statusNames[event.StatusSkipped]:
+		return true
+	default:
+		return false
+	}
+}
+
+// icon return the symbol that represent the current status of the row
+func (r *progressRow) icon(spinner string) string {
+	switch r.lastStatus {
+	case statusNames[event.StatusSuccessful]:
+		return progressSuccessIcon
+	case statusNames[event.StatusFailed]:
+		return progressFailureIcon
+	case statusNames[event.StatusSkipped]:
+		return progressSkippedIcon
+	case statusNames[event.StatusPending]:
+		return spinner
+	default:
+		return " "
+	}
+}
+
+// firstLine return the first line of message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
@@ -0,0 +1,147 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+// progressEvents return the events of a run where the deployment is waited and the service fails to apply
+func progressEvents() []event.Event {
+	deployment := testObject("apps/v1", "Deployment", "test", "nginx")
+	service := testObject("v1", "Service", "test", "nginx")
+	deploymentID := resource.ObjectMetadataFromUnstructured(deployment)
+
+	return []event.Event{
+		{Type: event.TypeQueue, QueueInfo: event.QueueInfo{Objects: []*unstructured.Unstructured{deployment, service}}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusPending}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusSuccessful}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: service, Status: event.StatusPending}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: service, Status: event.StatusFailed, Error: errors.New("invalid")}},
+		{Type: event.TypeStatusUpdate, StatusUpdateInfo: event.StatusUpdateInfo{Status: event.StatusPending, Message: "Deployment is not ready", ObjectMetadata: deploymentID}},
+		{Type: event.TypeStatusUpdate, StatusUpdateInfo: event.StatusUpdateInfo{Status: event.StatusPending, Message: "Deployment is not ready", ObjectMetadata: deploymentID}},
+		{Type: event.TypeStatusUpdate, StatusUpdateInfo: event.StatusUpdateInfo{Status: event.StatusSuccessful, Message: "Deployment is available", ObjectMetadata: deploymentID}},
+		{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusSuccessful}},
+	}
+}
+
+func TestProgressPrinterLines(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	printer := &progressPrinter{writer: buffer, now: fakeClock()}
+	require.NoError(t, printer.Print(eventsChannel(progressEvents()...)))
+
+	expected := `queue started for: [Deployment.apps nginx Service nginx]
+Deployment.apps nginx: apply started...
+Deployment.apps nginx: applied successfully
+Service nginx: apply started...
+Service nginx: failed to apply: invalid
+Deployment.apps nginx: Deployment is not ready
+Deployment.apps nginx: Deployment is available
+inventory: applied successfully
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestProgressPrinterInteractive(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	printer := &progressPrinter{
+		writer:          buffer,
+		now:             fakeClock(),
+		interactive:     true,
+		refreshInterval: time.Hour,
+	}
+	require.NoError(t, printer.Print(eventsChannel(progressEvents()...)))
+
+	expected := clearToScreenEnd + `  OBJECT                      APPLY       WAIT        PRUNE  ELAPSED  MESSAGE
+✓ Deployment.apps test/nginx  successful  successful  -      6s       Deployment is available
+✗ Service test/nginx          failed      -           -      1s       invalid
+inventory: applied successfully
+
+2 objects: 1 successful, 1 failed, 0 skipped
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestProgressStateRedraw(t *testing.T) {
+	t.Parallel()
+
+	deployment := testObject("apps/v1", "Deployment", "test", "nginx")
+	buffer := new(bytes.Buffer)
+	printer := &progressPrinter{writer: buffer, now: fakeClock(), interactive: true}
+	state := newProgressState()
+	state.add(NewRecord(event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusPending}}, testTime))
+
+	require.NoError(t, printer.render(state, false))
+	assert.Equal(t, 2, printer.renderedLines)
+	assert.Contains(t, buffer.String(), "| Deployment.apps test/nginx")
+
+	buffer.Reset()
+	require.NoError(t, printer.render(state, false))
+	assert.True(t, bytes.HasPrefix(buffer.Bytes(), []byte("\x1b[2A"+clearToScreenEnd)))
+}
+
+func TestProgressRenderTallerThanTerminal(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	printer := &progressPrinter{
+		writer:      buffer,
+		now:         fakeClock(),
+		interactive: true,
+		height:      func() int { return 5 },
+	}
+
+	state := newProgressState()
+	for idx := range 10 {
+		status := event.StatusSuccessful
+		if idx == 7 {
+			status = event.StatusPending
+		}
+		obj := testObject("v1", "ConfigMap", "test", fmt.Sprintf("config-%d", idx))
+		state.add(NewRecord(event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: obj, Status: status}}, testTime))
+	}
+
+	require.NoError(t, printer.render(state, false))
+	assert.Equal(t, 4, printer.renderedLines)
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	// the pending object is always shown, the remaining space is filled with the finished ones in order
+	assert.Contains(t, lines[1], "✓ ConfigMap test/config-0")
+	assert.Contains(t, lines[2], "| ConfigMap test/config-7")
+	assert.Equal(t, "  ... and 8 more objects", lines[3])
+
+	buffer.Reset()
+	require.NoError(t, printer.render(state, true))
+	assert.True(t, bytes.HasPrefix(buffer.Bytes(), []byte("\x1b[4A"+clearToScreenEnd)))
+	assert.NotContains(t, buffer.String(), "more objects")
+	assert.Contains(t, buffer.String(), "ConfigMap test/config-9")
+}
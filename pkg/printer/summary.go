@@ -0,0 +1,146 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"time"
+
+	"github.com/mia-platform/jpl/pkg/client"
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+const (
+	timedOutMessage = "timed out"
+)
+
+// objectResult contains the final outcome for an object found in the events
+type objectResult struct {
+	object  ObjectReference
+	action  string
+	status  string
+	message string
+}
+
+// summary contains the outcome of a run aggregated by object
+type summary struct {
+	objects []*objectResult
+	// errors contains the errors not related to a single object
+	errors []string
+	// warnings contains the messages of all the warning events
+	warnings []string
+
+	start time.Time
+	end   time.Time
+}
+
+// newSummary consume all the events in ch and aggregate them in a summary using now for tracking the duration,
+// the outcome of every object is the one computed by client.CollectResult
+func newSummary(ch <-chan event.Event, now func() time.Time) *summary {
+	s := &summary{
+		start: now(),
+	}
+
+	collector := client.NewResultCollector()
+	for e := range ch {
+		collector.Add(e)
+		s.add(NewRecord(e, now()))
+	}
+
+	for _, result := range collector.Result().Objects {
+		s.objects = append(s.objects, newObjectResult(result))
+	}
+
+	s.end = now()
+	return s
+}
+
+// add update the summary with the errors and warnings of record that are not related to a single object
+func (s *summary) add(record Record) {
+	switch record.Type {
+	case typeNames[event.TypeError]:
+		s.errors = append(s.errors, record.Error)
+	case typeNames[event.TypeWarning]:
+		s.warnings = append(s.warnings, record.Message)
+	case typeNames[event.TypeInventory]:
+		if record.Error != "" {
+			s.errors = append(s.errors, record.Message)
+		}
+	}
+}
+
+// newObjectResult return the objectResult for rendering result
+func newObjectResult(result client.ObjectResult) *objectResult {
+	objResult := &objectResult{
+		object: objectReferenceFromMetadata(result.Object),
+		status: outcomeStatus(result.Outcome),
+	}
+
+	switch result.Action {
+	case event.TypeApply, event.TypeReplace, event.TypePrune:
+		objResult.action = typeNames[result.Action]
+	case event.TypeDiff:
+		objResult.action = diffActionNames[result.DiffAction]
+	}
+
+	switch {
+	case result.Error != nil:
+		objResult.message = result.Error.Error()
+	case result.Outcome == client.OutcomeTimedOut && result.Message != "":
+		objResult.message = timedOutMessage + ": " + result.Message
+	case result.Outcome == client.OutcomeTimedOut:
+		objResult.message = timedOutMessage
+	default:
+		objResult.message = result.Message
+	}
+
+	return objResult
+}
+
+// outcomeStatus return the name of the status that represent outcome, the planned objects have no status
+func outcomeStatus(outcome client.Outcome) string {
+	switch outcome {
+	case client.OutcomeApplied, client.OutcomeCurrent, client.OutcomePruned:
+		return statusNames[event.StatusSuccessful]
+	case client.OutcomeFailed, client.OutcomePruneFailed, client.OutcomeTimedOut:
+		return statusNames[event.StatusFailed]
+	case client.OutcomeSkipped:
+		return statusNames[event.StatusSkipped]
+	default:
+		return ""
+	}
+}
+
+// objectReferenceFromMetadata return the ObjectReference for id
+func objectReferenceFromMetadata(id resource.ObjectMetadata) ObjectReference {
+	return ObjectReference{
+		Group:     id.Group,
+		Kind:      id.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+	}
+}
+
+// count return the number of objects that have ended with status
+func (s *summary) count(status event.Status) int {
+	count := 0
+	for _, result := range s.objects {
+		if result.status == statusNames[status] {
+			count++
+		}
+	}
+	return count
+}
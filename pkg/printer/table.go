@@ -0,0 +1,107 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 4
+	tablePadding  = 2
+	emptyCell     = "-"
+)
+
+// keep it to always check if tablePrinter implement correctly the Interface
+var _ Interface = &tablePrinter{}
+
+type tablePrinter struct {
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewTablePrinter return a printer that will wait for all the events and then write to w a table with the final
+// outcome of every object, followed by the count of the objects for every status and the errors of the run
+func NewTablePrinter(w io.Writer) Interface {
+	return &tablePrinter{
+		writer: w,
+		now:    time.Now,
+	}
+}
+
+// Print implement Interface
+func (p *tablePrinter) Print(ch <-chan event.Event) error {
+	s := newSummary(ch, p.now)
+
+	tw := tabwriter.NewWriter(p.writer, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tACTION\tSTATUS\tMESSAGE")
+	for _, result := range s.objects {
+		gk := result.object.Kind
+		if result.object.Group != "" {
+			gk += "." + result.object.Group
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			gk,
+			cell(result.object.Namespace),
+			result.object.Name,
+			cell(result.action),
+			cell(result.status),
+			cell(result.message),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(p.writer, "\n%d objects: %d successful, %d failed, %d skipped\n",
+		len(s.objects),
+		s.count(event.StatusSuccessful),
+		s.count(event.StatusFailed),
+		s.count(event.StatusSkipped),
+	); err != nil {
+		return err
+	}
+
+	for _, warning := range s.warnings {
+		if _, err := fmt.Fprintln(p.writer, warning); err != nil {
+			return err
+		}
+	}
+
+	for _, err := range s.errors {
+		if _, writeErr := fmt.Fprintf(p.writer, "error: %s\n", err); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return nil
+}
+
+// cell return value or a placeholder if value is empty
+func cell(value string) string {
+	if value == "" {
+		return emptyCell
+	}
+	return value
+}
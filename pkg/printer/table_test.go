@@ -0,0 +1,100 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+// runEvents return the events of a run with an applied, a failed, a skipped and a pruned object
+func runEvents() []event.Event {
+	deployment := testObject("apps/v1", "Deployment", "test", "nginx")
+	service := testObject("v1", "Service", "test", "nginx")
+	job := testObject("batch/v1", "Job", "test", "migration")
+	namespace := testObject("v1", "Namespace", "", "old")
+
+	return []event.Event{
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusPending}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: deployment, Status: event.StatusSuccessful}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: service, Status: event.StatusPending}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: service, Status: event.StatusFailed, Error: errors.New("invalid")}},
+		{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Object: job, Status: event.StatusSkipped, Error: resource.DependencyFailedError{Dependency: resource.ObjectMetadataFromUnstructured(service)}}},
+		{Type: event.TypeStatusUpdate, StatusUpdateInfo: event.StatusUpdateInfo{Status: event.StatusSuccessful, Message: "Deployment is available", ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment)}},
+		{Type: event.TypePrune, PruneInfo: event.PruneInfo{Object: namespace, Status: event.StatusPending}},
+		{Type: event.TypePrune, PruneInfo: event.PruneInfo{Object: namespace, Status: event.StatusSuccessful}},
+		{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusPending}},
+		{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusFailed, Error: errors.New("forbidden")}},
+	}
+}
+
+func TestTablePrinter(t *testing.T) {
+	t.Parallel()
+
+	buffer := new(bytes.Buffer)
+	printer := &tablePrinter{writer: buffer, now: fakeClock()}
+	require.NoError(t, printer.Print(eventsChannel(runEvents()...)))
+
+	expected := `KIND             NAMESPACE  NAME       ACTION  STATUS      MESSAGE
+Deployment.apps  test       nginx      apply   successful  Deployment is available
+Service          test       nginx      apply   failed      invalid
+Job.batch        test       migration  apply   skipped     dependency failed: Service test/nginx
+Namespace        -          old        prune   successful  -
+
+4 objects: 2 successful, 1 failed, 1 skipped
+error: inventory: failed to apply: forbidden
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestTablePrinterTimedOutPrune(t *testing.T) {
+	t.Parallel()
+
+	namespace := testObject("v1", "Namespace", "", "old")
+	events := []event.Event{
+		{Type: event.TypeQueue, QueueInfo: event.QueueInfo{
+			Objects: []*unstructured.Unstructured{namespace},
+			Steps: []event.Step{
+				{Type: event.StepPrune, Objects: []*unstructured.Unstructured{namespace}},
+				{Type: event.StepWaitDeletion, Objects: []*unstructured.Unstructured{namespace}},
+			},
+		}},
+		{Type: event.TypePrune, PruneInfo: event.PruneInfo{Object: namespace, Status: event.StatusSuccessful}},
+		{Type: event.TypeStatusUpdate, StatusUpdateInfo: event.StatusUpdateInfo{Status: event.StatusPending, Message: "Namespace is terminating", ObjectMetadata: resource.ObjectMetadataFromUnstructured(namespace)}},
+		{Type: event.TypeError, ErrorInfo: event.ErrorInfo{Error: context.DeadlineExceeded}},
+	}
+
+	buffer := new(bytes.Buffer)
+	printer := &tablePrinter{writer: buffer, now: fakeClock()}
+	require.NoError(t, printer.Print(eventsChannel(events...)))
+
+	expected := `KIND       NAMESPACE  NAME  ACTION  STATUS  MESSAGE
+Namespace  -          old   prune   failed  timed out: Namespace is terminating
+
+1 objects: 0 successful, 1 failed, 0 skipped
+error: context deadline exceeded
+`
+	assert.Equal(t, expected, buffer.String())
+}
@@ -46,11 +46,19 @@ type ExternalDependencyError struct {
 // Error implement error interface
 func (e ExternalDependencyError) Error() string {
 	return fmt.Sprintf("external dependency from %s to %s",
-		formatObjectMetadata(e.dependency.from),
+		formatObjectWithSource(e.dependency.from, e.dependency.fromSource),
 		formatObjectMetadata(e.dependency.to),
 	)
 }
 
+// formatObjectWithSource return the formatted metadata followed by source if it is known
+func formatObjectWithSource(metadata ObjectMetadata, source Source) string {
+	if source.Path == "" {
+		return formatObjectMetadata(metadata)
+	}
+	return fmt.Sprintf("%s (%s)", formatObjectMetadata(metadata), source)
+}
+
 func formatObjectMetadata(metadata ObjectMetadata) string {
 	gk := fmt.Sprintf("%s/%s", metadata.Group, metadata.Kind)
 	if metadata.Group == "" {
@@ -78,15 +86,33 @@ func (e CyclicDependencyError) Error() string {
 	builder.WriteString("cyclical dependencies:")
 	for _, dependency := range e.dependencies {
 		fmt.Fprintf(builder, "\n- from %s to %s",
-			formatObjectMetadata(dependency.from),
-			formatObjectMetadata(dependency.to),
+			formatObjectWithSource(dependency.from, dependency.fromSource),
+			formatObjectWithSource(dependency.to, dependency.toSource),
 		)
 	}
 
 	return builder.String()
 }
 
+// keep it to always check if DependencyFailedError implement correctly the error interface
+var _ error = DependencyFailedError{}
+
+// DependencyFailedError is used to signal that an object has not been applied because one of its dependencies
+// has failed
+type DependencyFailedError struct {
+	Dependency ObjectMetadata
+}
+
+// Error implement error interface
+func (e DependencyFailedError) Error() string {
+	return fmt.Sprintf("dependency failed: %s", formatObjectMetadata(e.Dependency))
+}
+
 type Dependency struct {
 	from ObjectMetadata
 	to   ObjectMetadata
+
+	// fromSource and toSource are the sources of the objects, if known
+	fromSource Source
+	toSource   Source
 }
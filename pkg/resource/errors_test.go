@@ -39,3 +39,34 @@ func TestCustomError(t *testing.T) {
 	assert.ErrorContains(t, err, unknownGVK.Version)
 	assert.ErrorContains(t, err, unknownGVK.Kind)
 }
+
+func TestDependencyFailedError(t *testing.T) {
+	t.Parallel()
+
+	err := DependencyFailedError{
+		Dependency: ObjectMetadata{Group: "apps", Kind: "Deployment", Namespace: "test", Name: "nginx"},
+	}
+	assert.EqualError(t, err, "dependency failed: apps/Deployment test/nginx")
+}
+
+func TestDependencyErrorsWithSource(t *testing.T) {
+	t.Parallel()
+
+	deployment := ObjectMetadata{Group: "apps", Kind: "Deployment", Namespace: "test", Name: "nginx"}
+	service := ObjectMetadata{Kind: "Service", Namespace: "test", Name: "nginx"}
+
+	externalErr := ExternalDependencyError{
+		dependency: Dependency{from: deployment, to: service, fromSource: Source{Path: "deployment.yaml", Line: 4}},
+	}
+	assert.EqualError(t, externalErr, "external dependency from apps/Deployment test/nginx (deployment.yaml:4) to Service test/nginx")
+
+	cyclicErr := CyclicDependencyError{
+		dependencies: []Dependency{
+			{from: deployment, to: service, fromSource: Source{Path: "deployment.yaml", Line: 4}},
+			{from: service, to: deployment, toSource: Source{Path: "deployment.yaml", Line: 4}},
+		},
+	}
+	assert.EqualError(t, cyclicErr, `cyclical dependencies:
+- from apps/Deployment test/nginx (deployment.yaml:4) to Service test/nginx
+- from Service test/nginx to apps/Deployment test/nginx (deployment.yaml:4)`)
+}
@@ -16,7 +16,6 @@
 package resource
 
 import (
-	"maps"
 	"sort"
 
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -45,8 +44,19 @@ func (g *DependencyGraph) addEdge(from, to *unstructured.Unstructured) {
 	edges.Insert(to)
 }
 
+// Dependencies return the objects that obj directly depends on, sorted
+func (g *DependencyGraph) Dependencies(obj *unstructured.Unstructured) []*unstructured.Unstructured {
+	dependencies := g.edges[obj].UnsortedList()
+	sort.Sort(SortableObjects(dependencies))
+	return dependencies
+}
+
 func (g *DependencyGraph) SortedResourceGroups() ([][]*unstructured.Unstructured, error) {
-	edges := maps.Clone(g.edges)
+	// clone also the sets to avoid losing the graph edges during the sorting
+	edges := make(map[*unstructured.Unstructured]sets.Set[*unstructured.Unstructured], len(g.edges))
+	for vertex, vertexEdges := range g.edges {
+		edges[vertex] = vertexEdges.Clone()
+	}
 
 	groups := make([][]*unstructured.Unstructured, 0)
 	for len(edges) > 0 {
@@ -60,10 +70,14 @@ func (g *DependencyGraph) SortedResourceGroups() ([][]*unstructured.Unstructured
 		if len(group) == 0 {
 			cyclicalDependencies := make([]Dependency, 0) //nolint: prealloc
 			for from, toList := range edges {
+				fromSource, _ := SourceOf(from)
 				for to := range toList {
+					toSource, _ := SourceOf(to)
 					cyclicalDependencies = append(cyclicalDependencies, Dependency{
-						from: ObjectMetadataFromUnstructured(from),
-						to:   ObjectMetadataFromUnstructured(to),
+						from:       ObjectMetadataFromUnstructured(from),
+						to:         ObjectMetadataFromUnstructured(to),
+						fromSource: fromSource,
+						toSource:   toSource,
 					})
 				}
 			}
@@ -84,8 +98,20 @@ func (g *DependencyGraph) SortedResourceGroups() ([][]*unstructured.Unstructured
 	return groups, nil
 }
 
-//gocyclo:ignore
 func NewDependencyGraph(objs []*unstructured.Unstructured) (*DependencyGraph, error) {
+	return newDependencyGraph(objs, true)
+}
+
+// NewLenientDependencyGraph return a DependencyGraph like NewDependencyGraph, but the explicit dependencies that
+// are malformed or point to objects outside of objs are ignored instead of returning an error. It can be used for
+// ordering objects already on the remote server, whose dependencies may be still applied or not exist anymore.
+func NewLenientDependencyGraph(objs []*unstructured.Unstructured) *DependencyGraph {
+	graph, _ := newDependencyGraph(objs, false)
+	return graph
+}
+
+//gocyclo:ignore
+func newDependencyGraph(objs []*unstructured.Unstructured, strict bool) (*DependencyGraph, error) {
 	graph := &DependencyGraph{
 		edges: make(map[*unstructured.Unstructured]sets.Set[*unstructured.Unstructured]),
 	}
@@ -136,7 +162,7 @@ func NewDependencyGraph(objs []*unstructured.Unstructured) (*DependencyGraph, er
 		}
 
 		dependencies, errors := dependendenciesForObj(obj, metadataLookup)
-		if len(errors) > 0 {
+		if len(errors) > 0 && strict {
 			accumulatedErrors = append(accumulatedErrors, errors...)
 		}
 		for _, dep := range dependencies {
@@ -156,9 +182,11 @@ func dependendenciesForObj(obj *unstructured.Unstructured, lookup map[ObjectMeta
 	accumulatedDependencies := make([]*unstructured.Unstructured, 0)
 	dependencies, err := ObjectExplicitDependencies(obj)
 	if err != nil {
-		accumulatedErrors = append(accumulatedErrors, err)
+		accumulatedErrors = append(accumulatedErrors, WithSource(obj, err))
 	}
 
+	source, _ := SourceOf(obj)
+
 	for _, dependencyMeta := range dependencies {
 		found := false
 		for objMeta, depObj := range lookup {
@@ -171,8 +199,9 @@ func dependendenciesForObj(obj *unstructured.Unstructured, lookup map[ObjectMeta
 		if !found {
 			accumulatedErrors = append(accumulatedErrors, ExternalDependencyError{
 				dependency: Dependency{
-					from: ObjectMetadataFromUnstructured(obj),
-					to:   dependencyMeta,
+					from:       ObjectMetadataFromUnstructured(obj),
+					to:         dependencyMeta,
+					fromSource: source,
 				},
 			})
 		}
@@ -208,6 +208,29 @@ func TestNewDependencyGraph(t *testing.T) {
 			},
 			expectedGraphError: "failed to parse object reference",
 		},
+		"error in parsing explicit dependencies reference the source": {
+			objects: []*unstructured.Unstructured{
+				func() *unstructured.Unstructured {
+					obj := webhookService.DeepCopy()
+					obj.SetAnnotations(map[string]string{
+						DependsOnAnnotation: "value",
+					})
+					SetSource(obj, Source{Path: "manifests/service.yaml", Line: 12})
+					return obj
+				}(),
+			},
+			expectedGraphError: "manifests/service.yaml:12: failed to parse object reference",
+		},
+		"error in missing resource of explicit dependencies reference the source": {
+			objects: []*unstructured.Unstructured{
+				func() *unstructured.Unstructured {
+					obj := annotatedWebhook.DeepCopy()
+					SetSource(obj, Source{Path: "manifests/webhook.yaml", Line: 1})
+					return obj
+				}(),
+			},
+			expectedGraphError: "external dependency from admissionregistration.k8s.io/ValidatingWebhookConfiguration example (manifests/webhook.yaml:1) to apps/Deployment test/nginx",
+		},
 		"error in missing resource of explicit dependencies": {
 			objects: []*unstructured.Unstructured{
 				annotatedWebhook,
@@ -255,3 +278,48 @@ func TestNewDependencyGraph(t *testing.T) {
 		})
 	}
 }
+
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+
+	testdata := "../../testdata/commons"
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespace.yaml"))
+	namespacedCRD := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespaced-crd.yaml"))
+	namespacedCR := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespaced-cr.yaml"))
+	namespacedCR.SetNamespace(namespace.GetName())
+
+	graph, err := NewDependencyGraph([]*unstructured.Unstructured{namespacedCR, namespace, namespacedCRD})
+	require.NoError(t, err)
+
+	_, err = graph.SortedResourceGroups()
+	require.NoError(t, err)
+
+	// the sorting must not consume the graph edges
+	assert.Equal(t, []*unstructured.Unstructured{namespace, namespacedCRD}, graph.Dependencies(namespacedCR))
+	assert.Empty(t, graph.Dependencies(namespace))
+	assert.Empty(t, graph.Dependencies(&unstructured.Unstructured{}))
+}
+
+func TestNewLenientDependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	testdata := "../../testdata/commons"
+	namespace := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "namespace.yaml"))
+	deployment := pkgtesting.UnstructuredFromFile(t, filepath.Join(testdata, "deployment.yaml"))
+	deployment.SetNamespace(namespace.GetName())
+	require.NoError(t, SetObjectExplicitDependencies(deployment, []ObjectMetadata{
+		ObjectMetadataFromUnstructured(namespace),
+		{Kind: "ConfigMap", Namespace: namespace.GetName(), Name: "missing"},
+	}))
+
+	objects := []*unstructured.Unstructured{deployment, namespace}
+	_, err := NewDependencyGraph(objects)
+	require.Error(t, err)
+
+	graph := NewLenientDependencyGraph(objects)
+	assert.Equal(t, []*unstructured.Unstructured{namespace}, graph.Dependencies(deployment))
+
+	groups, err := graph.SortedResourceGroups()
+	require.NoError(t, err)
+	assert.Equal(t, [][]*unstructured.Unstructured{{namespace}, {deployment}}, groups)
+}
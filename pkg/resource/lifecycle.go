@@ -0,0 +1,52 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// LifecycleDeletionAnnotation can be set on an object to change what happens when it must be deleted
+	LifecycleDeletionAnnotation = "client.lifecycle.config.k8s.io/deletion"
+	// PreventDeletion is the LifecycleDeletionAnnotation value for detaching the object from the inventory
+	// instead of deleting it from the remote server
+	PreventDeletion = "detach"
+
+	// OnRemoveAnnotation can be set on an object to change what happens when it is removed from the applied objects
+	OnRemoveAnnotation = "cli-utils.sigs.k8s.io/on-remove"
+	// OnRemoveKeep is the OnRemoveAnnotation value for keeping the object on the remote server
+	OnRemoveKeep = "keep"
+
+	// ReplaceAnnotation can be set on an object to change what happens when it cannot be updated
+	ReplaceAnnotation = "client.lifecycle.config.k8s.io/replace"
+	// ReplaceOnImmutable is the ReplaceAnnotation value for deleting and creating again the object when the apply
+	// fails for changes to immutable fields
+	ReplaceOnImmutable = "on-immutable-change"
+)
+
+// IsDeletionPrevented return true if obj has been annotated for never being deleted from the remote server when
+// it must be pruned, in this case it only has to be removed from the inventory
+func IsDeletionPrevented(obj *unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+	return annotations[LifecycleDeletionAnnotation] == PreventDeletion || annotations[OnRemoveAnnotation] == OnRemoveKeep
+}
+
+// IsReplaceOnImmutable return true if obj has been annotated for being deleted and created again when the apply
+// fails for changes to immutable fields
+func IsReplaceOnImmutable(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[ReplaceAnnotation] == ReplaceOnImmutable
+}
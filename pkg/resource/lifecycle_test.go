@@ -0,0 +1,61 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsDeletionPrevented(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		"no annotations": {
+			expected: false,
+		},
+		"prevent deletion annotation": {
+			annotations: map[string]string{LifecycleDeletionAnnotation: PreventDeletion},
+			expected:    true,
+		},
+		"keep on remove annotation": {
+			annotations: map[string]string{OnRemoveAnnotation: OnRemoveKeep},
+			expected:    true,
+		},
+		"unknown annotations values": {
+			annotations: map[string]string{
+				LifecycleDeletionAnnotation: "delete",
+				OnRemoveAnnotation:          "remove",
+			},
+			expected: false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(testCase.annotations)
+			assert.Equal(t, testCase.expected, IsDeletionPrevented(obj))
+		})
+	}
+}
@@ -16,9 +16,6 @@
 package resource
 
 import (
-	"fmt"
-	"os"
-
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -27,7 +24,8 @@ import (
 )
 
 // Scope will lookup the resource against the provided mapper for getting its scope. If the mapper return
-// a resource type not found error, we will search inside the additionalCRDs Unstructured objects.
+// a resource type not found error, we will search inside the additionalCRDs Unstructured objects, skipping the ones
+// that are not valid, see ValidateCRD.
 // If no addtionalCRDs are passed or the type is not found even in those objects we return an UnknownResourceTypesError.
 func Scope(obj *unstructured.Unstructured, mapper meta.RESTMapper, addtionalCRDs []*unstructured.Unstructured) (meta.RESTScope, error) {
 	gvk := obj.GroupVersionKind()
@@ -43,9 +41,8 @@ func Scope(obj *unstructured.Unstructured, mapper meta.RESTMapper, addtionalCRDs
 
 	// if remote lookup return withour result, try to found on additionalCRDs resources
 	for _, crd := range addtionalCRDs {
-		var typedCRD apiextensionsv1.CustomResourceDefinition
-		if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(crd.Object, &typedCRD, true); err != nil {
-			fmt.Fprintf(os.Stderr, "skipping invalid CRD found: %q", err)
+		typedCRD, err := typedCRD(crd)
+		if err != nil {
 			continue
 		}
 
@@ -64,3 +61,16 @@ func Scope(obj *unstructured.Unstructured, mapper meta.RESTMapper, addtionalCRDs
 
 	return nil, UnknownResourceTypeError{ResourceGVK: gvk}
 }
+
+// ValidateCRD return an error if crd cannot be converted to a valid CustomResourceDefinition
+func ValidateCRD(crd *unstructured.Unstructured) error {
+	_, err := typedCRD(crd)
+	return err
+}
+
+// typedCRD convert crd to a CustomResourceDefinition, returning an error if it contains unknown fields
+func typedCRD(crd *unstructured.Unstructured) (apiextensionsv1.CustomResourceDefinition, error) {
+	var typedCRD apiextensionsv1.CustomResourceDefinition
+	err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(crd.Object, &typedCRD, true)
+	return typedCRD, err
+}
@@ -107,3 +107,14 @@ func TestResourceScope(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCRD(t *testing.T) {
+	t.Parallel()
+
+	testdataFolder := filepath.Join("..", "..", "testdata", "commons")
+	deploymentFilename := filepath.Join(testdataFolder, "deployment.yaml")
+	clusterCRDFilename := filepath.Join(testdataFolder, "cluster-crd.yaml")
+
+	assert.NoError(t, ValidateCRD(pkgtesting.UnstructuredFromFile(t, clusterCRDFilename)))
+	assert.Error(t, ValidateCRD(pkgtesting.UnstructuredFromFile(t, deploymentFilename)))
+}
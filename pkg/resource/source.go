@@ -0,0 +1,121 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"weak"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sources is the side-table that keep track of where the objects have been read from, without storing it inside
+// the objects; the entries are removed when their objects are garbage collected
+var sources = struct {
+	lock    sync.RWMutex
+	entries map[weak.Pointer[unstructured.Unstructured]]Source
+}{
+	entries: make(map[weak.Pointer[unstructured.Unstructured]]Source),
+}
+
+// Source identify where an object has been read from
+type Source struct {
+	// Path is the file containing the object, or stdin if it has been read from a stream
+	Path string
+	// Document is the index of the yaml document of the object inside Path, starting from zero
+	Document int
+	// Line is the line of Path where the object starts, zero if it is not known
+	Line int
+}
+
+// String return the source in the path:line format, or only the path if the line is not known
+func (s Source) String() string {
+	if s.Line == 0 {
+		return s.Path
+	}
+	return fmt.Sprintf("%s:%d", s.Path, s.Line)
+}
+
+// SetSource record source as the origin of obj, it is not propagated to the copies of obj
+func SetSource(obj *unstructured.Unstructured, source Source) {
+	key := weak.Make(obj)
+
+	sources.lock.Lock()
+	defer sources.lock.Unlock()
+	if _, found := sources.entries[key]; !found {
+		runtime.AddCleanup(obj, removeSource, key)
+	}
+	sources.entries[key] = source
+}
+
+// SourceOf return the source recorded for obj, and if it has been found
+func SourceOf(obj *unstructured.Unstructured) (Source, bool) {
+	if obj == nil {
+		return Source{}, false
+	}
+
+	sources.lock.RLock()
+	defer sources.lock.RUnlock()
+	source, found := sources.entries[weak.Make(obj)]
+	return source, found
+}
+
+// removeSource delete the source of an object that has been garbage collected
+func removeSource(key weak.Pointer[unstructured.Unstructured]) {
+	sources.lock.Lock()
+	defer sources.lock.Unlock()
+	delete(sources.entries, key)
+}
+
+// keep it to always check if SourceError implement correctly the error interface
+var _ error = SourceError{}
+
+// SourceError add to Err the source of the object that has caused it
+type SourceError struct {
+	Source Source
+	Err    error
+}
+
+// Error implement error interface
+func (e SourceError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Source, e.Err)
+}
+
+// Unwrap return the original error
+func (e SourceError) Unwrap() error {
+	return e.Err
+}
+
+// WithSource return err wrapped in a SourceError if the source of obj is known and err does not already contain
+// it, otherwise err is returned as is
+func WithSource(obj *unstructured.Unstructured, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sourceErr SourceError
+	if errors.As(err, &sourceErr) {
+		return err
+	}
+
+	if source, found := SourceOf(obj); found {
+		return SourceError{Source: source, Err: err}
+	}
+	return err
+}
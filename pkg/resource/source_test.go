@@ -0,0 +1,89 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSourceString(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source   Source
+		expected string
+	}{
+		"path and line": {
+			source:   Source{Path: "manifests/deployment.yaml", Document: 1, Line: 12},
+			expected: "manifests/deployment.yaml:12",
+		},
+		"path without line": {
+			source:   Source{Path: "manifests/deployment.yaml"},
+			expected: "manifests/deployment.yaml",
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, testCase.source.String())
+		})
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	t.Parallel()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "ConfigMap"}}
+	source, found := SourceOf(obj)
+	assert.False(t, found)
+	assert.Empty(t, source)
+
+	expected := Source{Path: "configmap.yaml", Document: 2, Line: 20}
+	SetSource(obj, expected)
+	source, found = SourceOf(obj)
+	assert.True(t, found)
+	assert.Equal(t, expected, source)
+
+	// the source is not part of the object and is not copied with it
+	assert.Equal(t, map[string]interface{}{"kind": "ConfigMap"}, obj.Object)
+	_, found = SourceOf(obj.DeepCopy())
+	assert.False(t, found)
+
+	_, found = SourceOf(nil)
+	assert.False(t, found)
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("unexpected field composition")
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	assert.Nil(t, WithSource(obj, nil))
+	assert.Equal(t, errTest, WithSource(obj, errTest))
+
+	SetSource(obj, Source{Path: "deployment.yaml", Line: 3})
+	err := WithSource(obj, errTest)
+	assert.EqualError(t, err, "deployment.yaml:3: unexpected field composition")
+	assert.ErrorIs(t, err, errTest)
+
+	// the source is added only once
+	assert.Equal(t, err, WithSource(obj, err))
+}
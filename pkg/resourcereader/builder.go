@@ -30,7 +30,31 @@ func NewResourceReaderBuilder(f util.ClientFactory) Builder {
 }
 
 type builder struct {
-	factory util.ClientFactory
+	factory            util.ClientFactory
+	kustomize          bool
+	disableRemoteBases bool
+	substitution       *Substitution
+	filter             FileFilter
+}
+
+func (b *builder) WithKustomize() Builder {
+	b.kustomize = true
+	return b
+}
+
+func (b *builder) WithoutRemoteBases() Builder {
+	b.disableRemoteBases = true
+	return b
+}
+
+func (b *builder) WithSubstitution(substitution *Substitution) Builder {
+	b.substitution = substitution
+	return b
+}
+
+func (b *builder) WithFileFilter(filter FileFilter) Builder {
+	b.filter = filter
+	return b
 }
 
 // ResourceReader implement the Builder interface
@@ -49,18 +73,27 @@ func (b *builder) ResourceReader(reader io.Reader, path string) (Reader, error)
 		Mapper:           mapper,
 		Namespace:        namespace,
 		EnforceNamespace: enforceNamespace,
+		Substitution:     b.substitution,
 	}
 
 	var resourceReader Reader
-	if path == StdinPath {
+	switch {
+	case path == StdinPath:
 		resourceReader = &StreamReader{
 			Reader:        reader,
 			ReaderConfigs: readerConfig,
 		}
-	} else {
+	case b.kustomize && isKustomization(path):
+		resourceReader = &KustomizeReader{
+			Path:               path,
+			ReaderConfigs:      readerConfig,
+			DisableRemoteBases: b.disableRemoteBases,
+		}
+	default:
 		resourceReader = &FilepathReader{
 			Path:          path,
 			ReaderConfigs: readerConfig,
+			Filter:        b.filter,
 		}
 	}
 
@@ -36,6 +36,7 @@ func TestBuilder(t *testing.T) {
 		expectedType      interface{}
 		reader            io.Reader
 		path              string
+		kustomize         bool
 	}{
 		"new stream reader": {
 			testFactory:  pkgtesting.NewTestClientFactory(),
@@ -49,6 +50,19 @@ func TestBuilder(t *testing.T) {
 			path:         filepath.Join("a", "valid", "path"),
 			expectedType: &FilepathReader{},
 		},
+		"new kustomize reader": {
+			testFactory:  pkgtesting.NewTestClientFactory(),
+			reader:       strings.NewReader(""),
+			path:         filepath.Join("testdata", "kustomize"),
+			kustomize:    true,
+			expectedType: &KustomizeReader{},
+		},
+		"kustomization directory is read as files without kustomize": {
+			testFactory:  pkgtesting.NewTestClientFactory(),
+			reader:       strings.NewReader(""),
+			path:         filepath.Join("testdata", "kustomize"),
+			expectedType: &FilepathReader{},
+		},
 	}
 
 	for testName, testCase := range testCases {
@@ -57,6 +71,9 @@ func TestBuilder(t *testing.T) {
 
 			builder := NewResourceReaderBuilder(testCase.testFactory)
 			require.NotNil(t, builder)
+			if testCase.kustomize {
+				builder = builder.WithKustomize()
+			}
 
 			reader, err := builder.ResourceReader(testCase.reader, testCase.path)
 			require.NoError(t, err)
@@ -64,3 +81,17 @@ func TestBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilderFileFilter(t *testing.T) {
+	t.Parallel()
+
+	filter := FileFilter{Include: []string{"*.yaml"}, Exclude: []string{"tests/**"}}
+	reader, err := NewResourceReaderBuilder(pkgtesting.NewTestClientFactory()).
+		WithFileFilter(filter).
+		ResourceReader(nil, filepath.Join("a", "valid", "path"))
+	require.NoError(t, err)
+
+	filepathReader, ok := reader.(*FilepathReader)
+	require.True(t, ok)
+	assert.Equal(t, filter, filepathReader.Filter)
+}
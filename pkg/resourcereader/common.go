@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -28,8 +29,10 @@ import (
 	"github.com/mia-platform/jpl/pkg/resource"
 )
 
-// objectsFromReader will create a kio.Pipeline for reading data from a Reader and cast it to a series of Resources
-func objectsFromReader(reader kio.Reader) ([]*unstructured.Unstructured, error) {
+// objectsFromReader will create a kio.Pipeline for reading data from a Reader and cast it to a series of Resources;
+// if reader does not omit its annotations, the source of every object is recorded joining sourceRoot with the
+// path found in them and using lines for finding where the object starts
+func objectsFromReader(reader kio.Reader, sourceRoot string, lines sourceLines) ([]*unstructured.Unstructured, error) {
 	var objs []*unstructured.Unstructured
 
 	pipeline := kio.Pipeline{
@@ -37,6 +40,11 @@ func objectsFromReader(reader kio.Reader) ([]*unstructured.Unstructured, error)
 		Filters: []kio.Filter{filters.StripCommentsFilter{}, &filters.IsLocalConfig{}},
 		Outputs: []kio.Writer{kio.WriterFunc(func(nodes []*yaml.RNode) error {
 			for _, node := range nodes {
+				source, err := nodeSource(node, sourceRoot, lines)
+				if err != nil {
+					return err
+				}
+
 				data, err := node.MarshalJSON()
 				if err != nil {
 					return err
@@ -50,6 +58,9 @@ func objectsFromReader(reader kio.Reader) ([]*unstructured.Unstructured, error)
 				obj := &unstructured.Unstructured{
 					Object: object,
 				}
+				if source.Path != "" {
+					resource.SetSource(obj, source)
+				}
 				objs = append(objs, obj)
 			}
 
@@ -64,6 +75,30 @@ func objectsFromReader(reader kio.Reader) ([]*unstructured.Unstructured, error)
 	return objs, nil
 }
 
+// objectsFromPackage read the objects from the file or the directory at path inside fSys, expanding them with
+// substituter; the files of a directory are selected using filter and the ignore files found inside it
+func objectsFromPackage(fSys filesys.FileSystem, path string, substituter *substituter, filter FileFilter) ([]*unstructured.Unstructured, error) {
+	root := packageRoot(fSys, path)
+	lines := make(sourceLines)
+	reader := &kio.LocalPackageReader{
+		PackagePath: path,
+		FileSystem:  filesys.FileSystemOrOnDisk{FileSystem: lines.fileSystem(substituter.fileSystem(fSys, nil), root)},
+
+		// the annotations are needed for recording the source of the objects and are removed afterwards
+		OmitReaderAnnotations: false,
+	}
+
+	if fSys.IsDir(path) {
+		matcher, err := newFileMatcher(fSys, path, filter)
+		if err != nil {
+			return nil, err
+		}
+		reader.FileSkipFunc = matcher.skip
+	}
+
+	return objectsFromReader(reader, root, lines)
+}
+
 // setNamespace will set the namespace property for every Namespaced resource to the value provided
 // if is not already set.
 // If enforce is set to true we will return an error containing all the resources with mismatched namespace.
@@ -78,7 +113,7 @@ func setNamespace(mapper meta.RESTMapper, objs []*unstructured.Unstructured, nam
 	for _, res := range objs {
 		scope, err := resource.Scope(res, mapper, crds)
 		if err != nil {
-			return err
+			return resource.WithSource(res, err)
 		}
 
 		objNamespace := res.GetNamespace()
@@ -86,17 +121,19 @@ func setNamespace(mapper meta.RESTMapper, objs []*unstructured.Unstructured, nam
 		case meta.RESTScopeNamespace:
 			switch {
 			case enforce && objNamespace != "" && objNamespace != namespace:
+				source, _ := resource.SourceOf(res)
 				return EnforcedNamespaceError{
 					EnforcedNamespace: namespace,
 					NamespaceFound:    objNamespace,
 					ResourceGVK:       res.GroupVersionKind(),
+					Source:            source,
 				}
 			case objNamespace == "":
 				res.SetNamespace(namespace)
 			}
 		case meta.RESTScopeRoot:
 			if objNamespace != "" {
-				return fmt.Errorf("resource %q has cluster scope but has namespace set to %q", res.GroupVersionKind(), objNamespace)
+				return resource.WithSource(res, fmt.Errorf("resource %q has cluster scope but has namespace set to %q", res.GroupVersionKind(), objNamespace))
 			}
 		}
 	}
@@ -136,6 +136,27 @@ func TestSetNamespace(t *testing.T) {
 				},
 			},
 		},
+		"enforce namespace return error with the source of the resource": {
+			objs: []*unstructured.Unstructured{
+				func() *unstructured.Unstructured {
+					obj := pkgtesting.UnstructuredFromFile(t, secretFilename)
+					resource.SetSource(obj, resource.Source{Path: "manifests/secret.yaml", Line: 3})
+					return obj
+				}(),
+			},
+			namespace:          testNamespace,
+			enforceNamespace:   true,
+			expectedNamespaces: []string{"secret"},
+			expectedErr: EnforcedNamespaceError{
+				EnforcedNamespace: testNamespace,
+				NamespaceFound:    "secret",
+				ResourceGVK: schema.GroupVersionKind{
+					Version: "v1",
+					Kind:    "Secret",
+				},
+				Source: resource.Source{Path: "manifests/secret.yaml", Line: 3},
+			},
+		},
 	}
 
 	mapper, err := pkgtesting.NewTestClientFactory().ToRESTMapper()
@@ -156,3 +177,17 @@ func TestSetNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestEnforcedNamespaceErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	err := EnforcedNamespaceError{
+		EnforcedNamespace: "test",
+		NamespaceFound:    "secret",
+		ResourceGVK:       schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+	}
+	assert.EqualError(t, err, `found resource "/v1, Kind=Secret" in namespace "secret", but all resources must be in namespace "test"`)
+
+	err.Source = resource.Source{Path: "manifests/secret.yaml", Line: 3}
+	assert.EqualError(t, err, `manifests/secret.yaml:3: found resource "/v1, Kind=Secret" in namespace "secret", but all resources must be in namespace "test"`)
+}
@@ -17,8 +17,11 @@ package resourcereader
 
 import (
 	"fmt"
+	"io/fs"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/mia-platform/jpl/pkg/resource"
 )
 
 // keep it to always check if EnforcedNamespaceError implement correctly the error interface
@@ -29,10 +32,51 @@ type EnforcedNamespaceError struct {
 	EnforcedNamespace string
 	NamespaceFound    string
 	ResourceGVK       schema.GroupVersionKind
+	// Source is where the resource has been read from, if known
+	Source resource.Source
 }
 
 // Error implements the error interface
 func (e EnforcedNamespaceError) Error() string {
-	return fmt.Sprintf("found resource %q in namespace %q, but all resources must be in namespace %q",
+	message := fmt.Sprintf("found resource %q in namespace %q, but all resources must be in namespace %q",
 		e.ResourceGVK, e.NamespaceFound, e.EnforcedNamespace)
+	if e.Source.Path == "" {
+		return message
+	}
+	return fmt.Sprintf("%s: %s", e.Source, message)
+}
+
+// keep it to always check if RemoteReferenceError implement correctly the error interface
+var _ error = RemoteReferenceError{}
+
+// RemoteReferenceError is used if a kustomization reference a resource that is not available locally when the
+// remote bases are disabled
+type RemoteReferenceError struct {
+	Kustomization string
+	Reference     string
+}
+
+// Error implements the error interface
+func (e RemoteReferenceError) Error() string {
+	return fmt.Sprintf("%q references %q that is not a local file or directory, but remote bases are disabled",
+		e.Kustomization, e.Reference)
+}
+
+// keep it to always check if MissingReferenceError implement correctly the error interface
+var _ error = MissingReferenceError{}
+
+// MissingReferenceError is used if a kustomization reference a local file or directory that does not exist
+type MissingReferenceError struct {
+	Kustomization string
+	Reference     string
+}
+
+// Error implements the error interface
+func (e MissingReferenceError) Error() string {
+	return fmt.Sprintf("%q references %q that does not exist", e.Kustomization, e.Reference)
+}
+
+// Unwrap return fs.ErrNotExist for allowing to check the error with errors.Is
+func (e MissingReferenceError) Unwrap() error {
+	return fs.ErrNotExist
 }
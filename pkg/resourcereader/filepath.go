@@ -19,7 +19,7 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 // keep it to always check if FilepathReader implement correctly the Reader interface
@@ -29,17 +29,22 @@ type FilepathReader struct {
 	ReaderConfigs
 
 	Path string
+	// Filter select the files to read when Path is a directory, the files matched by the IgnoreFileName files
+	// found inside it are always skipped
+	Filter FileFilter
 }
 
 // Read implement the Reader interface
 func (r *FilepathReader) Read() ([]*unstructured.Unstructured, error) {
-	reader := &kio.LocalPackageReader{
-		PackagePath: r.Path,
-
-		OmitReaderAnnotations: true,
+	substituter, err := newSubstituter(r.Substitution)
+	if err != nil {
+		return nil, err
 	}
 
-	objs, err := objectsFromReader(reader)
+	objs, err := objectsFromPackage(filesys.MakeFsOnDisk(), r.Path, substituter, r.Filter)
+	if err == nil {
+		err = substituter.err()
+	}
 	if err != nil {
 		return objs, fmt.Errorf("fail to read from path %q: %w", r.Path, err)
 	}
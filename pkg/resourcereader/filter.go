@@ -0,0 +1,166 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+	gitignore "github.com/monochromegane/go-gitignore"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// IgnoreFileName is the name of the files containing the patterns, in the .gitignore format, of the files that
+// must be skipped when reading the directory where they are found and its subdirectories
+const IgnoreFileName = ".jplignore"
+
+// FileFilter select the files to read inside a directory. The patterns support the `*`, `**`, `?`, `[...]` and
+// `{a,b}` wildcards: patterns without a slash are matched against the name of the files, the others against
+// their path relative to the directory read, separated by slashes.
+type FileFilter struct {
+	// Include if not empty contains the patterns of the files to read, the files not matching any of them are skipped
+	Include []string
+	// Exclude contains the patterns of the files to skip, it takes precedence over Include
+	Exclude []string
+}
+
+// filePattern is a compiled pattern of a FileFilter
+type filePattern struct {
+	glob glob.Glob
+	// name is true if the pattern must be matched against the name of the file instead of its path
+	name bool
+}
+
+// match return true if the file at the slash separated relPath matches the pattern
+func (p filePattern) match(relPath string) bool {
+	if p.name {
+		return p.glob.Match(path.Base(relPath))
+	}
+	return p.glob.Match(relPath)
+}
+
+// fileMatcher decide which files of a directory must be skipped following a FileFilter and the ignore files
+type fileMatcher struct {
+	include []filePattern
+	exclude []filePattern
+	// ignores contains the matchers of the ignore files keyed by the slash separated path of their directory
+	// relative to the root
+	ignores map[string]gitignore.IgnoreMatcher
+}
+
+// newFileMatcher return the fileMatcher for the directory root of fSys, reading all the ignore files inside it
+func newFileMatcher(fSys filesys.FileSystem, root string, filter FileFilter) (*fileMatcher, error) {
+	include, err := compilePatterns(filter.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(filter.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	ignores := make(map[string]gitignore.IgnoreMatcher)
+	err = fSys.Walk(root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != IgnoreFileName {
+			return nil
+		}
+
+		data, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		dir, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		dir = filepath.ToSlash(dir)
+		ignores[dir] = gitignore.NewGitIgnoreFromReader(dir, bytes.NewReader(data))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s files: %w", IgnoreFileName, err)
+	}
+
+	return &fileMatcher{include: include, exclude: exclude, ignores: ignores}, nil
+}
+
+// compilePatterns return the filePattern of every pattern or an error if any of them is invalid
+func compilePatterns(patterns []string) ([]filePattern, error) {
+	compiled := make([]filePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "./")
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, filePattern{glob: g, name: !strings.Contains(pattern, "/")})
+	}
+	return compiled, nil
+}
+
+// skip return true if the file at relPath, relative to the root, must not be read; it can be used as
+// kio.LocalPackageSkipFileFunc
+func (m *fileMatcher) skip(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if m.ignored(relPath) {
+		return true
+	}
+
+	for _, pattern := range m.exclude {
+		if pattern.match(relPath) {
+			return true
+		}
+	}
+
+	if len(m.include) == 0 {
+		return false
+	}
+	for _, pattern := range m.include {
+		if pattern.match(relPath) {
+			return false
+		}
+	}
+	return true
+}
+
+// ignored return true if relPath, or one of its parent directories, is matched by the ignore file of one of the
+// directories that contain it
+func (m *fileMatcher) ignored(relPath string) bool {
+	for dir, ignore := range m.ignores {
+		if dir != "." && !strings.HasPrefix(relPath, dir+"/") {
+			continue
+		}
+
+		if ignore.Match(relPath, false) {
+			return true
+		}
+		for parent := path.Dir(relPath); parent != dir && parent != "."; parent = path.Dir(parent) {
+			if ignore.Match(parent, true) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFilter(t *testing.T) {
+	t.Parallel()
+
+	configMap := func(name string) []byte {
+		return fmt.Appendf(nil, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n", name)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		IgnoreFileName:                 []byte("# skipped files\nignored/\n*.tmp.yaml\n"),
+		"root.yaml":                    configMap("root"),
+		"root.tmp.yaml":                configMap("root-tmp"),
+		"ignored/ignored.yaml":         configMap("ignored"),
+		"apps/app.yaml":                configMap("app"),
+		"apps/app-test.yaml":           configMap("app-test"),
+		"apps/" + IgnoreFileName:       []byte("*-test.yaml\n"),
+		"apps/nested/nested.yml":       configMap("nested"),
+		"apps/nested/nested-test.yaml": configMap("nested-test"),
+		"other/" + IgnoreFileName:      []byte("app.yaml\n"),
+		"other/app-test.yaml":          configMap("other-app-test"),
+		"other/app.yaml":               configMap("other-app"),
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), content, 0600))
+	}
+
+	testCases := map[string]struct {
+		path          string
+		filter        FileFilter
+		expectedNames []string
+		expectedError string
+	}{
+		"ignore files are applied to their directory and subdirectories": {
+			path:          dir,
+			expectedNames: []string{"app", "nested", "other-app-test", "root"},
+		},
+		"include patterns with and without slash": {
+			path:          dir,
+			filter:        FileFilter{Include: []string{"apps/**", "root.*"}},
+			expectedNames: []string{"app", "nested", "root"},
+		},
+		"exclude take precedence over include": {
+			path:          dir,
+			filter:        FileFilter{Include: []string{"apps/**"}, Exclude: []string{"*.yml"}},
+			expectedNames: []string{"app"},
+		},
+		"filter of nested directory": {
+			path:          filepath.Join(dir, "apps"),
+			filter:        FileFilter{Exclude: []string{"./nested/*"}},
+			expectedNames: []string{"app"},
+		},
+		"filter is not applied to single files": {
+			path:          filepath.Join(dir, "root.tmp.yaml"),
+			filter:        FileFilter{Exclude: []string{"*"}},
+			expectedNames: []string{"root-tmp"},
+		},
+		"invalid pattern": {
+			path:          dir,
+			filter:        FileFilter{Include: []string{"[a-"}},
+			expectedError: "invalid file pattern \"[a-\"",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := (&FilepathReader{Path: testCase.path, Filter: testCase.filter}).Read()
+			if len(testCase.expectedError) > 0 {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(objects))
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+			}
+			assert.ElementsMatch(t, testCase.expectedNames, names)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// ErrMissingFS is used when a FSReader is used without setting its FS
+var ErrMissingFS = errors.New("file system is required for reading from it")
+
+// keep it to always check if FSReader implement correctly the Reader interface
+var _ Reader = &FSReader{}
+
+// FSReader is a concrete implementation of Reader that will parse the manifests found inside a fs.FS, like the
+// embed.FS of a binary that ship its own manifests, following the same rules of the FilepathReader
+type FSReader struct {
+	ReaderConfigs
+
+	FS fs.FS
+	// Path is the file or directory to read inside FS, it must be a valid fs.FS path and default to the root of FS
+	Path string
+	// Filter select the files to read when Path is a directory, the files matched by the IgnoreFileName files
+	// found inside it are always skipped
+	Filter FileFilter
+}
+
+// Read implement the Reader interface
+func (r *FSReader) Read() ([]*unstructured.Unstructured, error) {
+	path := r.Path
+	if path == "" {
+		path = "."
+	}
+
+	substituter, err := newSubstituter(r.Substitution)
+	if err != nil {
+		return nil, err
+	}
+
+	fSys, err := inMemoryCopy(r.FS, path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read from fs path %q: %w", path, err)
+	}
+
+	objs, err := objectsFromPackage(fSys, path, substituter, r.Filter)
+	if err == nil {
+		err = substituter.err()
+	}
+	if err != nil {
+		return objs, fmt.Errorf("fail to read from fs path %q: %w", path, err)
+	}
+
+	err = setNamespace(r.Mapper, objs, r.Namespace, r.EnforceNamespace)
+	return objs, err
+}
+
+// inMemoryCopy return an in memory filesys.FileSystem containing a copy of the file or directory at root of fsys,
+// keeping the same paths; the files are copied only in memory for reusing the kio readers
+func inMemoryCopy(fsys fs.FS, root string) (filesys.FileSystem, error) {
+	if fsys == nil {
+		return nil, ErrMissingFS
+	}
+
+	memFS := filesys.MakeFsInMemory()
+	err := fs.WalkDir(fsys, root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return memFS.MkdirAll(path)
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		return memFS.WriteFile(path, data)
+	})
+	return memFS, err
+}
@@ -0,0 +1,111 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"embed"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+//go:embed testdata/multiple-resources.yaml testdata/namespaced-secret.yaml
+var embeddedManifests embed.FS
+
+func TestFSReader(t *testing.T) {
+	t.Parallel()
+
+	testdataFolder := filepath.Join("..", "..", "testdata", "commons")
+	deployment := pkgtesting.ReadBytesFromFile(t, filepath.Join(testdataFolder, "deployment.yaml"))
+	namespace := pkgtesting.ReadBytesFromFile(t, filepath.Join(testdataFolder, "namespace.yaml"))
+	local := pkgtesting.ReadBytesFromFile(t, filepath.Join("testdata", "local.yaml"))
+	invalid := pkgtesting.ReadBytesFromFile(t, filepath.Join("testdata", "invalid.yaml"))
+
+	mapFS := fstest.MapFS{
+		"manifests/deployment.yaml":      {Data: deployment},
+		"manifests/nested/namespace.yml": {Data: namespace},
+		"manifests/local.yaml":           {Data: local},
+		"manifests/README.md":            {Data: []byte("# manifests")},
+		"invalid/invalid.yaml":           {Data: invalid},
+	}
+
+	testCases := map[string]struct {
+		reader          *FSReader
+		expectedSources []string
+		expectedError   string
+	}{
+		"read directory, filtering local manifests": {
+			reader:          &FSReader{FS: mapFS, Path: "manifests"},
+			expectedSources: []string{"manifests/deployment.yaml:1", "manifests/nested/namespace.yml:1"},
+		},
+		"read single file": {
+			reader:          &FSReader{FS: mapFS, Path: "manifests/nested/namespace.yml"},
+			expectedSources: []string{"manifests/nested/namespace.yml:1"},
+		},
+		"read embedded file system from the root": {
+			reader:          &FSReader{FS: embeddedManifests},
+			expectedSources: []string{"testdata/multiple-resources.yaml:1", "testdata/multiple-resources.yaml:29", "testdata/namespaced-secret.yaml:1"},
+		},
+		"read with filter": {
+			reader: &FSReader{
+				FS:     mapFS,
+				Path:   "manifests",
+				Filter: FileFilter{Include: []string{"nested/**"}},
+			},
+			expectedSources: []string{"manifests/nested/namespace.yml:1"},
+		},
+		"read invalid manifest": {
+			reader:        &FSReader{FS: mapFS, Path: "invalid"},
+			expectedError: "fail to read from fs path \"invalid\"",
+		},
+		"missing path": {
+			reader:        &FSReader{FS: mapFS, Path: "missing"},
+			expectedError: "fail to read from fs path \"missing\"",
+		},
+		"missing file system": {
+			reader:        &FSReader{Path: "manifests"},
+			expectedError: ErrMissingFS.Error(),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := testCase.reader.Read()
+			if len(testCase.expectedError) > 0 {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				assert.Empty(t, objects)
+				return
+			}
+
+			require.NoError(t, err)
+			sources := make([]string, 0, len(objects))
+			for _, obj := range objects {
+				source, found := resource.SourceOf(obj)
+				assert.True(t, found)
+				sources = append(sources, source.String())
+			}
+			assert.Equal(t, testCase.expectedSources, sources)
+		})
+	}
+}
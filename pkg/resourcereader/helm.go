@@ -0,0 +1,200 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+const (
+	// HelmHookAnnotation contains the comma separated list of hooks of an object rendered from a chart
+	HelmHookAnnotation = "helm.sh/hook"
+	// HelmHookWeightAnnotation contains the weight used by Helm for ordering the hooks of the same type
+	HelmHookWeightAnnotation = "helm.sh/hook-weight"
+	// HelmHookDeletePolicyAnnotation contains the policies used by Helm for removing the hooks objects
+	HelmHookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+
+	// HelmTestHook is the hook of the objects used by helm test for checking a release
+	HelmTestHook = "test"
+	// HelmTestSuccessHook is the legacy name of HelmTestHook
+	HelmTestSuccessHook = "test-success"
+
+	helmNotesSuffix = "NOTES.txt"
+)
+
+// ErrMissingReleaseName is used when a chart is rendered without a release name
+var ErrMissingReleaseName = errors.New("release name is required for rendering a chart")
+
+var _ Reader = &HelmReader{}
+
+// HelmReader render the templates of a local Helm chart and read the resulting objects, the objects rendered from
+// the chart hooks are returned with the other ones keeping their hooks annotations. The files in the crds directories
+// of the chart and its dependencies are returned before the templates, while the chart tests are skipped
+type HelmReader struct {
+	ReaderConfigs
+
+	// ChartPath is the path of a chart directory or of a packaged chart archive
+	ChartPath string
+	// ReleaseName is the name of the release used for rendering the templates, the release namespace is the
+	// Namespace of the ReaderConfigs or default if it is not set
+	ReleaseName string
+	// ValuesFiles are merged in order on top of the default values of the chart, the last one has precedence
+	ValuesFiles []string
+	// IncludeTests will return also the objects of the chart tests, that otherwise will be applied on every run
+	IncludeTests bool
+}
+
+func (r *HelmReader) Read() ([]*unstructured.Unstructured, error) {
+	manifests, err := r.render()
+	if err != nil {
+		return nil, fmt.Errorf("fail to render chart %q: %w", r.ChartPath, err)
+	}
+
+	reader := &kio.ByteReader{
+		Reader:                strings.NewReader(manifests),
+		OmitReaderAnnotations: true,
+	}
+
+	objs, err := objectsFromReader(reader, "", nil)
+	if err != nil {
+		return objs, fmt.Errorf("fail to read chart %q: %w", r.ChartPath, err)
+	}
+
+	if !r.IncludeTests {
+		objs = slices.DeleteFunc(objs, isHelmTest)
+	}
+
+	err = setNamespace(r.Mapper, objs, r.Namespace, r.EnforceNamespace)
+	return objs, err
+}
+
+// render return the crds and all the manifests rendered from the chart templates as a single yaml stream
+func (r *HelmReader) render() (string, error) {
+	if r.ReleaseName == "" {
+		return "", ErrMissingReleaseName
+	}
+
+	chart, err := loader.Load(r.ChartPath)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]interface{})
+	for _, valuesFile := range r.ValuesFiles {
+		fileValues, err := chartutil.ReadValuesFile(valuesFile)
+		if err != nil {
+			return "", err
+		}
+		values = mergeValues(values, fileValues.AsMap())
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chart, values); err != nil {
+		return "", err
+	}
+
+	namespace := r.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	options := chartutil.ReleaseOptions{
+		Name:      r.ReleaseName,
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(chart, values, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := engine.Render(chart, renderValues)
+	if err != nil {
+		return "", err
+	}
+
+	// the crds are not templated and must precede the objects that can use them
+	var manifests strings.Builder
+	for _, crd := range chart.CRDObjects() {
+		manifests.WriteString("---\n")
+		manifests.Write(crd.File.Data)
+		manifests.WriteString("\n")
+	}
+
+	// the templates are concatenated following their names to keep the objects order stable between runs
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		content := files[name]
+		if strings.HasSuffix(name, helmNotesSuffix) || strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		manifests.WriteString("---\n")
+		manifests.WriteString(content)
+		manifests.WriteString("\n")
+	}
+
+	return manifests.String(), nil
+}
+
+// mergeValues return a copy of base with override merged on top of it, nested maps are merged recursively
+// while every other value is replaced. A null in override is kept instead of deleting the key, because deleting it
+// would restore the chart default, while a null is removed together with the default when the values are
+// coalesced with the chart, like helm does for its values files
+func mergeValues(base, override map[string]interface{}) map[string]interface{} {
+	merged := maps.Clone(base)
+	for key, value := range override {
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			if baseMap, ok := merged[key].(map[string]interface{}); ok {
+				merged[key] = mergeValues(baseMap, valueMap)
+				continue
+			}
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// HelmHooks return the Helm hooks set on obj, an empty slice means that obj is not a hook
+func HelmHooks(obj *unstructured.Unstructured) []string {
+	value := obj.GetAnnotations()[HelmHookAnnotation]
+	if value == "" {
+		return nil
+	}
+
+	hooks := strings.Split(value, ",")
+	for idx, hook := range hooks {
+		hooks[idx] = strings.TrimSpace(hook)
+	}
+	return hooks
+}
+
+// isHelmTest return true if obj is run only by helm test
+func isHelmTest(obj *unstructured.Unstructured) bool {
+	hooks := HelmHooks(obj)
+	return slices.Contains(hooks, HelmTestHook) || slices.Contains(hooks, HelmTestSuccessHook)
+}
@@ -0,0 +1,132 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestHelmReader(t *testing.T) {
+	t.Parallel()
+
+	chartPath := filepath.Join("testdata", "chart")
+	valuesPath := filepath.Join("testdata", "chart-values.yaml")
+	nullValuesPath := filepath.Join("testdata", "chart-null-values.yaml")
+
+	chart, err := loader.Load(chartPath)
+	require.NoError(t, err)
+	archivePath, err := chartutil.Save(chart, t.TempDir())
+	require.NoError(t, err)
+	mapper, err := pkgtesting.NewTestClientFactory().ToRESTMapper()
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		reader        *HelmReader
+		expectedNames []string
+		expectedImage string
+		expectedHooks []string
+		expectedError string
+	}{
+		"render chart directory with default values": {
+			reader: &HelmReader{
+				ChartPath:     chartPath,
+				ReleaseName:   "release",
+				ReaderConfigs: ReaderConfigs{Mapper: mapper, Namespace: "test"},
+			},
+			expectedNames: []string{"widgets.example.com", "release-test-chart", "release-test-chart-migration"},
+			expectedImage: "nginx:latest",
+			expectedHooks: []string{"pre-install", "pre-upgrade"},
+		},
+		"render chart with tests": {
+			reader: &HelmReader{
+				ChartPath:     chartPath,
+				ReleaseName:   "release",
+				IncludeTests:  true,
+				ReaderConfigs: ReaderConfigs{Mapper: mapper, Namespace: "test"},
+			},
+			expectedNames: []string{
+				"widgets.example.com",
+				"release-test-chart",
+				"release-test-chart-migration",
+				"release-test-chart-test-connection",
+			},
+			expectedImage: "nginx:latest",
+			expectedHooks: []string{"pre-install", "pre-upgrade", "test"},
+		},
+		"render chart archive with values file": {
+			reader: &HelmReader{
+				ChartPath:   archivePath,
+				ReleaseName: "release",
+				ValuesFiles: []string{valuesPath},
+			},
+			expectedNames: []string{"widgets.example.com", "release-test-chart"},
+			expectedImage: "nginx:stable",
+		},
+		"null in a later values file removes the value": {
+			reader: &HelmReader{
+				ChartPath:   chartPath,
+				ReleaseName: "release",
+				ValuesFiles: []string{valuesPath, nullValuesPath},
+			},
+			expectedNames: []string{"widgets.example.com", "release-test-chart"},
+			expectedImage: "nginx:",
+		},
+		"missing release name": {
+			reader:        &HelmReader{ChartPath: chartPath},
+			expectedError: ErrMissingReleaseName.Error(),
+		},
+		"missing chart": {
+			reader:        &HelmReader{ChartPath: filepath.Join("testdata", "missing"), ReleaseName: "release"},
+			expectedError: "fail to render chart",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := testCase.reader.Read()
+			if len(testCase.expectedError) > 0 {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(objects))
+			var hooks []string
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+				if obj.GetKind() == "Deployment" {
+					containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+					require.NoError(t, err)
+					assert.Equal(t, testCase.expectedImage, containers[0].(map[string]interface{})["image"])
+				}
+				hooks = append(hooks, HelmHooks(obj)...)
+			}
+			assert.Equal(t, testCase.expectedNames, names)
+			assert.Equal(t, testCase.expectedHooks, hooks)
+		})
+	}
+}
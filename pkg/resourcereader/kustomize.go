@@ -0,0 +1,261 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+var _ Reader = &KustomizeReader{}
+
+// KustomizeReader build in-process the kustomization found in Path and read the resulting objects
+type KustomizeReader struct {
+	ReaderConfigs
+
+	Path string
+	// DisableRemoteBases make the build fail if the kustomization, or one of its local bases and components,
+	// reference a remote base or file, for building it without network access
+	DisableRemoteBases bool
+}
+
+func (r *KustomizeReader) Read() ([]*unstructured.Unstructured, error) {
+	substituter, err := newSubstituter(r.Substitution)
+	if err != nil {
+		return nil, err
+	}
+
+	fSys := filesys.MakeFsOnDisk()
+	if r.DisableRemoteBases {
+		if err := checkLocalReferences(fSys, r.Path, sets.New[string]()); err != nil {
+			return nil, fmt.Errorf("fail to build kustomization %q: %w", r.Path, err)
+		}
+	}
+
+	if substituter != nil {
+		manifests := sets.New[string]()
+		if err := collectManifests(fSys, r.Path, manifests, sets.New[string]()); err != nil {
+			return nil, fmt.Errorf("fail to build kustomization %q: %w", r.Path, err)
+		}
+		fSys = substituter.fileSystem(fSys, manifests)
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, r.Path)
+	if err == nil {
+		err = substituter.err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to build kustomization %q: %w", r.Path, err)
+	}
+
+	objs, err := objectsFromReader(&kio.PackageBuffer{Nodes: resMap.ToRNodeSlice()}, "", nil)
+	if err != nil {
+		return objs, fmt.Errorf("fail to read kustomization %q: %w", r.Path, err)
+	}
+
+	err = setNamespace(r.Mapper, objs, r.Namespace, r.EnforceNamespace)
+	return objs, err
+}
+
+// isKustomization return true if path is a directory containing a kustomization file
+func isKustomization(path string) bool {
+	return kustomizationFile(filesys.MakeFsOnDisk(), path) != ""
+}
+
+// kustomizationFile return the path of the kustomization file contained in dir, or an empty string if dir is not
+// a directory or it does not contain a kustomization
+func kustomizationFile(fSys filesys.FileSystem, dir string) string {
+	if !fSys.IsDir(dir) {
+		return ""
+	}
+
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if path := filepath.Join(dir, name); fSys.Exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// remoteReferencePrefixes are the prefixes of the references that kustomize retrieve via git without a url scheme
+var remoteReferencePrefixes = []string{"git@", "git::", "github.com/", "gitlab.com/", "bitbucket.org/"}
+
+// checkLocalReferences return a RemoteReferenceError if the kustomization in dir, or one of the local bases and
+// components that it references, contains a remote reference, and a MissingReferenceError if one of the local
+// files or directories that they reference is not present on fSys
+func checkLocalReferences(fSys filesys.FileSystem, dir string, visited sets.Set[string]) error {
+	file, kustomization, err := readKustomization(fSys, dir, visited)
+	if kustomization == nil {
+		return err
+	}
+
+	for _, reference := range kustomizationReferences(kustomization) {
+		path := reference
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, reference)
+		}
+
+		switch {
+		case fSys.IsDir(path):
+			if err := checkLocalReferences(fSys, path, visited); err != nil {
+				return err
+			}
+		case fSys.Exists(path):
+			// local files are read by kustomize without downloading them
+		case isRemoteReference(reference):
+			return RemoteReferenceError{Kustomization: file, Reference: reference}
+		default:
+			return MissingReferenceError{Kustomization: file, Reference: reference}
+		}
+	}
+
+	return nil
+}
+
+// collectManifests add to manifests the cleaned paths of the local resources, crds and patches files of the
+// kustomization in dir and of the local bases and components that it references; the other files read by kustomize,
+// like the generators sources, are not manifests and are left out
+func collectManifests(fSys filesys.FileSystem, dir string, manifests, visited sets.Set[string]) error {
+	_, kustomization, err := readKustomization(fSys, dir, visited)
+	if kustomization == nil {
+		return err
+	}
+
+	references := slices.Concat(kustomization.Resources, kustomization.Components, kustomization.Crds)
+	for _, patch := range slices.Concat(kustomization.Patches, kustomization.PatchesJson6902) {
+		references = append(references, patch.Path)
+	}
+	for _, patch := range kustomization.PatchesStrategicMerge {
+		references = append(references, string(patch))
+	}
+
+	for _, reference := range references {
+		if reference == "" || strings.Contains(reference, "\n") {
+			continue
+		}
+
+		path := reference
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, reference)
+		}
+
+		switch {
+		case fSys.IsDir(path):
+			if err := collectManifests(fSys, path, manifests, visited); err != nil {
+				return err
+			}
+		case fSys.Exists(path):
+			manifests.Insert(cleanedPath(fSys, path))
+		}
+	}
+
+	return nil
+}
+
+// readKustomization return the path and the content of the kustomization file in dir, the returned kustomization
+// is nil if dir does not contain a kustomization or if it has been already visited
+func readKustomization(fSys filesys.FileSystem, dir string, visited sets.Set[string]) (string, *types.Kustomization, error) {
+	file := kustomizationFile(fSys, dir)
+	if file == "" || visited.Has(file) {
+		return file, nil, nil
+	}
+	visited.Insert(file)
+
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return file, nil, err
+	}
+
+	kustomization := &types.Kustomization{}
+	if err := kustomization.Unmarshal(data); err != nil {
+		return file, nil, err
+	}
+	kustomization.FixKustomization()
+	return file, kustomization, nil
+}
+
+// cleanedPath return the absolute path of path without symlinks, as it is opened by kustomize
+func cleanedPath(fSys filesys.FileSystem, path string) string {
+	dir, file, err := fSys.CleanedAbs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return dir.Join(file)
+}
+
+// kustomizationReferences return the files and directories referenced by kustomization, in its resources,
+// components, crds, patches, generators and transformers, skipping the ones that are defined inline
+func kustomizationReferences(kustomization *types.Kustomization) []string {
+	references := slices.Concat(kustomization.Resources, kustomization.Components, kustomization.Crds,
+		kustomization.Generators, kustomization.Transformers, kustomization.Validators)
+
+	for _, patch := range slices.Concat(kustomization.Patches, kustomization.PatchesJson6902) {
+		references = append(references, patch.Path)
+	}
+	for _, patch := range kustomization.PatchesStrategicMerge {
+		references = append(references, string(patch))
+	}
+
+	generators := make([]types.GeneratorArgs, 0, len(kustomization.ConfigMapGenerator)+len(kustomization.SecretGenerator))
+	for _, generator := range kustomization.ConfigMapGenerator {
+		generators = append(generators, generator.GeneratorArgs)
+	}
+	for _, generator := range kustomization.SecretGenerator {
+		generators = append(generators, generator.GeneratorArgs)
+	}
+	for _, generator := range generators {
+		for _, source := range generator.FileSources {
+			// file sources can be in the key=path form
+			if _, path, found := strings.Cut(source, "="); found {
+				source = path
+			}
+			references = append(references, source)
+		}
+		references = append(references, generator.EnvSources...)
+	}
+
+	return slices.DeleteFunc(references, func(reference string) bool {
+		return reference == "" || strings.Contains(reference, "\n")
+	})
+}
+
+// isRemoteReference return true if reference, that is not present locally, is an url or a git repository that
+// kustomize will download
+func isRemoteReference(reference string) bool {
+	if parsed, err := url.Parse(reference); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		return true
+	}
+
+	for _, prefix := range remoteReferencePrefixes {
+		if strings.HasPrefix(reference, prefix) {
+			return true
+		}
+	}
+
+	return strings.Contains(reference, "?ref=") || strings.Contains(reference, "?version=") ||
+		strings.Contains(reference, ".git//") || strings.HasSuffix(reference, ".git")
+}
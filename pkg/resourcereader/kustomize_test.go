@@ -0,0 +1,227 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestKustomizeReader(t *testing.T) {
+	t.Parallel()
+
+	testdataFolder := filepath.Join("..", "..", "testdata", "commons")
+	deploymentFilename := filepath.Join(testdataFolder, "deployment.yaml")
+	namespaceFilename := filepath.Join(testdataFolder, "namespace.yaml")
+	localFilename := filepath.Join("testdata", "local.yaml")
+
+	testCases := map[string]struct {
+		manifests          map[string][]byte
+		disableRemoteBases bool
+		expectedNames      []string
+		expectedError      string
+	}{
+		"build overlay": {
+			manifests: map[string][]byte{
+				"base/kustomization.yaml": []byte("resources:\n- deployment.yaml\n- namespace.yaml\n- local.yaml\n"),
+				"base/deployment.yaml":    pkgtesting.ReadBytesFromFile(t, deploymentFilename),
+				"base/namespace.yaml":     pkgtesting.ReadBytesFromFile(t, namespaceFilename),
+				"base/local.yaml":         pkgtesting.ReadBytesFromFile(t, localFilename),
+				"kustomization.yaml":      []byte("namePrefix: overlay-\nresources:\n- base\n"),
+			},
+			disableRemoteBases: true,
+			expectedNames:      []string{"overlay-nginx", "test"},
+		},
+		"remote base are refused when disabled": {
+			manifests: map[string][]byte{
+				"base/kustomization.yaml": []byte("resources:\n- https://github.com/example/repo//base?ref=v1.0.0\n"),
+				"kustomization.yaml":      []byte("resources:\n- base\n"),
+			},
+			disableRemoteBases: true,
+			expectedError:      "remote bases are disabled",
+		},
+		"remote git base without scheme are refused when disabled": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("resources:\n- github.com/example/repo/base?ref=v1.0.0\n"),
+			},
+			disableRemoteBases: true,
+			expectedError:      "remote bases are disabled",
+		},
+		"remote patch are refused when disabled": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("resources:\n- deployment.yaml\npatches:\n- path: https://example.com/patch.yaml\n"),
+				"deployment.yaml":    pkgtesting.ReadBytesFromFile(t, deploymentFilename),
+			},
+			disableRemoteBases: true,
+			expectedError:      "remote bases are disabled",
+		},
+		"local files in directories named like remote hosts are not refused": {
+			manifests: map[string][]byte{
+				"kustomization.yaml":                 []byte("resources:\n- github.com/example/deployment.yaml\n"),
+				"github.com/example/deployment.yaml": pkgtesting.ReadBytesFromFile(t, deploymentFilename),
+			},
+			disableRemoteBases: true,
+			expectedNames:      []string{"nginx"},
+		},
+		"build with local patches and generators": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte(`resources:
+- deployment.yaml
+patches:
+- path: patch.yaml
+- patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+  target:
+    kind: Deployment
+configMapGenerator:
+- name: config
+  files:
+  - app.properties=config/app.properties
+  envs:
+  - config/env
+generatorOptions:
+  disableNameSuffixHash: true
+`),
+				"deployment.yaml":       pkgtesting.ReadBytesFromFile(t, deploymentFilename),
+				"patch.yaml":            []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: nginx\n  labels:\n    patched: \"true\"\n"),
+				"config/app.properties": []byte("key=value\n"),
+				"config/env":            []byte("ENV=test\n"),
+			},
+			disableRemoteBases: true,
+			expectedNames:      []string{"nginx", "config"},
+		},
+		"missing local resource is not reported as remote": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("resources:\n- deploymnt.yaml\n"),
+			},
+			disableRemoteBases: true,
+			expectedError:      `references "deploymnt.yaml" that does not exist`,
+		},
+		"missing patch file": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("resources:\n- deployment.yaml\npatchesStrategicMerge:\n- patch.yaml\n"),
+				"deployment.yaml":    pkgtesting.ReadBytesFromFile(t, deploymentFilename),
+			},
+			disableRemoteBases: true,
+			expectedError:      `references "patch.yaml" that does not exist`,
+		},
+		"missing generator file": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("configMapGenerator:\n- name: config\n  files:\n  - app.properties\n"),
+			},
+			disableRemoteBases: true,
+			expectedError:      `references "app.properties" that does not exist`,
+		},
+		"missing crd file": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("crds:\n- crd.json\n"),
+			},
+			disableRemoteBases: true,
+			expectedError:      `references "crd.json" that does not exist`,
+		},
+		"invalid kustomization": {
+			manifests: map[string][]byte{
+				"kustomization.yaml": []byte("resources:\n- missing.yaml\n"),
+			},
+			expectedError: "fail to build kustomization",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for filePath, content := range testCase.manifests {
+				p := filepath.Join(dir, filePath)
+				require.NoError(t, os.MkdirAll(filepath.Dir(p), 0700))
+				require.NoError(t, os.WriteFile(p, content, 0600))
+			}
+
+			reader := &KustomizeReader{Path: dir, DisableRemoteBases: testCase.disableRemoteBases}
+			objects, err := reader.Read()
+			if len(testCase.expectedError) > 0 {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(objects))
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+				assert.Empty(t, obj.GetAnnotations(), "kustomize internal annotations must be removed")
+			}
+			assert.ElementsMatch(t, testCase.expectedNames, names)
+		})
+	}
+}
+
+func TestKustomizeReaderSubstitution(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"kustomization.yaml": `resources:
+- configmap.yaml
+patches:
+- path: patch.yaml
+configMapGenerator:
+- name: scripts
+  files:
+  - run.sh
+  - template.txt
+generatorOptions:
+  disableNameSuffixHash: true
+`,
+		"configmap.yaml": "# ${UNSET}\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ${NAME}\ndata:\n  host: example.com\n",
+		"patch.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ${NAME}\ndata:\n  host: {{ .HOST }}\n",
+		"run.sh":         "#!/bin/sh\necho ${SHELL_ONLY_VAR}\n",
+		"template.txt":   "{{ .NotAValue }}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	reader := &KustomizeReader{
+		Path: dir,
+		ReaderConfigs: ReaderConfigs{Substitution: &Substitution{
+			Variables:       map[string]string{"NAME": "config", "HOST": "substituted.example.com"},
+			EnableTemplates: true,
+		}},
+	}
+	objects, err := reader.Read()
+	require.NoError(t, err)
+
+	data := make(map[string]interface{})
+	for _, obj := range objects {
+		data[obj.GetName()] = obj.Object["data"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"config": map[string]interface{}{"host": "substituted.example.com"},
+		"scripts": map[string]interface{}{
+			"run.sh":       "#!/bin/sh\necho ${SHELL_ONLY_VAR}\n",
+			"template.txt": "{{ .NotAValue }}\n",
+		},
+	}, data)
+}
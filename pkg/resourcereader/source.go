@@ -0,0 +1,194 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/mia-platform/jpl/pkg/resource"
+)
+
+// readerAnnotations are the annotations added by the kio readers for tracking the origin of the nodes, they are
+// removed from the objects and their values are recorded as their source instead
+var readerAnnotations = []string{
+	kioutil.PathAnnotation,
+	kioutil.IndexAnnotation,
+	kioutil.IdAnnotation,
+	kioutil.SeqIndentAnnotation,
+	kioutil.LegacyPathAnnotation,
+	kioutil.LegacyIndexAnnotation,
+	kioutil.LegacyIdAnnotation,
+}
+
+// documentSeparator is the same expression used by kio.ByteReader for splitting the yaml documents
+var documentSeparator = regexp.MustCompile(`\n---.*\n`)
+
+// nodeSource return the source of node built from the reader annotations and remove them from node
+func nodeSource(node *yaml.RNode, sourceRoot string, lines sourceLines) (resource.Source, error) {
+	path, index, _ := kioutil.GetFileAnnotations(node)
+	var source resource.Source
+	switch {
+	case path != "":
+		source.Path = filepath.Join(sourceRoot, path)
+	case index != "":
+		source.Path = sourceRoot
+	default:
+		return resource.Source{}, nil
+	}
+
+	if index != "" {
+		document, err := strconv.Atoi(index)
+		if err != nil {
+			return resource.Source{}, fmt.Errorf("invalid document index %q: %w", index, err)
+		}
+		source.Document = document
+	}
+	source.Line = lines.line(path, source.Document, node.YNode().Line)
+
+	for _, annotation := range readerAnnotations {
+		if err := node.PipeE(yaml.ClearAnnotation(annotation)); err != nil {
+			return resource.Source{}, err
+		}
+	}
+	return source, yaml.ClearEmptyAnnotations(node)
+}
+
+// sourceLines keep the line where every yaml document starts for every file read, keyed by its path relative to
+// the package root like in the kio path annotation; the nodes returned by kio count their lines from the start of
+// their own document
+type sourceLines map[string][]int
+
+// record save the lines where the documents of content start for key
+func (l sourceLines) record(key string, content []byte) {
+	l[key] = documentStarts(string(content))
+}
+
+// line return the line of the file recorded as key where the line found in document is, or zero if the file has
+// not been recorded
+func (l sourceLines) line(key string, document, line int) int {
+	starts, found := l[key]
+	if !found || document >= len(starts) {
+		return 0
+	}
+	return starts[document] + line
+}
+
+// reader return a reader that return the same content of reader recording it as key
+func (l sourceLines) reader(key string, reader io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	l.record(key, data)
+	return bytes.NewReader(data), nil
+}
+
+// fileSystem return fSys wrapped for recording the content of every file opened from it below the root directory
+func (l sourceLines) fileSystem(fSys filesys.FileSystem, root string) filesys.FileSystem {
+	absoluteRoot := root
+	if dir, _, err := fSys.CleanedAbs(root); err == nil {
+		absoluteRoot = string(dir)
+	}
+	return &recordingFileSystem{FileSystem: fSys, lines: l, root: absoluteRoot}
+}
+
+// documentStarts return the number of lines that precede every non empty yaml document in content, following
+// the same order used by kio.ByteReader for the index annotation
+func documentStarts(content string) []int {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var starts []int
+	appendDocument := func(start, end int) {
+		if !emptyDocument(content[start:end]) {
+			starts = append(starts, strings.Count(content[:start], "\n"))
+		}
+	}
+
+	previous := 0
+	for _, location := range documentSeparator.FindAllStringIndex(content, -1) {
+		appendDocument(previous, location[0])
+		previous = location[1]
+	}
+	appendDocument(previous, len(content))
+	return starts
+}
+
+// emptyDocument return true if document does not contain any yaml node, malformed documents are not considered
+// empty because the reader will fail on them
+func emptyDocument(document string) bool {
+	node := &yaml.Node{}
+	if err := yaml.NewDecoder(strings.NewReader(document)).Decode(node); err != nil {
+		return err == io.EOF
+	}
+	return yaml.IsYNodeEmptyDoc(node) || yaml.IsMissingOrNull(yaml.NewRNode(node))
+}
+
+// packageRoot return the directory that the paths read by a kio.LocalPackageReader for path are relative to
+func packageRoot(fSys filesys.FileSystem, path string) string {
+	if !fSys.IsDir(path) {
+		return filepath.Dir(path)
+	}
+	return path
+}
+
+// recordingFileSystem is a filesys.FileSystem that record the lines of the files opened from it
+type recordingFileSystem struct {
+	filesys.FileSystem
+	lines sourceLines
+	root  string
+}
+
+// Open implement filesys.FileSystem, the returned file will read the content that has been recorded
+func (fs *recordingFileSystem) Open(path string) (filesys.File, error) {
+	file, err := fs.FileSystem.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := filepath.Rel(fs.root, path)
+	if err != nil {
+		key = path
+	}
+
+	reader, err := fs.lines.reader(key, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &bufferedFile{File: file, reader: reader}, nil
+}
+
+// bufferedFile is a filesys.File that read from a copy of the content of the original file
+type bufferedFile struct {
+	filesys.File
+	reader io.Reader
+}
+
+// Read implement io.Reader
+func (f *bufferedFile) Read(p []byte) (int, error) {
+	return f.reader.Read(p)
+}
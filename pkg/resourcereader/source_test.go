@@ -0,0 +1,135 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/jpl/pkg/resource"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestReadersSource(t *testing.T) {
+	t.Parallel()
+
+	multipleResources := pkgtesting.ReadBytesFromFile(t, filepath.Join("testdata", "multiple-resources.yaml"))
+	manifest := []byte(`# leading comment
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+# empty document
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+  annotations:
+    example.com/annotation: value
+`)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "configmaps.yaml"), manifest, 0600))
+
+	tests := map[string]struct {
+		reader          Reader
+		expectedSources []resource.Source
+	}{
+		"filepath reader with directory": {
+			reader: &FilepathReader{Path: dir},
+			expectedSources: []resource.Source{
+				{Path: filepath.Join(dir, "nested", "configmaps.yaml"), Document: 0, Line: 2},
+				{Path: filepath.Join(dir, "nested", "configmaps.yaml"), Document: 1, Line: 9},
+			},
+		},
+		"filepath reader with file": {
+			reader: &FilepathReader{Path: filepath.Join(dir, "nested", "configmaps.yaml")},
+			expectedSources: []resource.Source{
+				{Path: filepath.Join(dir, "nested", "configmaps.yaml"), Document: 0, Line: 2},
+				{Path: filepath.Join(dir, "nested", "configmaps.yaml"), Document: 1, Line: 9},
+			},
+		},
+		"stream reader": {
+			reader: &StreamReader{Reader: bytes.NewReader(multipleResources)},
+			expectedSources: []resource.Source{
+				{Path: "stdin", Document: 0, Line: 1},
+				{Path: "stdin", Document: 2, Line: 29},
+			},
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			objs, err := testCase.reader.Read()
+			require.NoError(t, err)
+			require.Len(t, objs, len(testCase.expectedSources))
+
+			for idx, obj := range objs {
+				source, found := resource.SourceOf(obj)
+				assert.True(t, found)
+				assert.Equal(t, testCase.expectedSources[idx], source)
+
+				// the reader annotations must not be left on the objects
+				for key := range obj.GetAnnotations() {
+					assert.NotContains(t, readerAnnotations, key)
+				}
+			}
+			assert.Nil(t, objs[0].GetAnnotations())
+		})
+	}
+}
+
+func TestDocumentStarts(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		content  string
+		expected []int
+	}{
+		"single document": {
+			content:  "kind: ConfigMap\n",
+			expected: []int{0},
+		},
+		"empty documents are skipped": {
+			content:  "---\nkind: ConfigMap\n---\n# comment\n---\n\nkind: Secret\n",
+			expected: []int{0, 5},
+		},
+		"windows line endings": {
+			content:  "kind: ConfigMap\r\n---\r\nkind: Secret\r\n",
+			expected: []int{0, 2},
+		},
+		"empty content": {
+			content: "",
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, documentStarts(testCase.content))
+		})
+	}
+}
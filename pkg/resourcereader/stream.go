@@ -32,12 +32,32 @@ type StreamReader struct {
 
 // Read implement the Reader interface
 func (r *StreamReader) Read() ([]*unstructured.Unstructured, error) {
+	substituter, err := newSubstituter(r.Substitution)
+	if err != nil {
+		return nil, err
+	}
+
+	expandedReader, err := substituter.reader(stdinName, r.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read from stream: %w", err)
+	}
+
+	lines := make(sourceLines)
+	recordedReader, err := lines.reader("", expandedReader)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read from stream: %w", err)
+	}
+
 	reader := &kio.ByteReader{
-		Reader:                r.Reader,
-		OmitReaderAnnotations: true,
+		Reader: recordedReader,
+		// the annotations are needed for recording the source of the objects and are removed afterwards
+		OmitReaderAnnotations: false,
 	}
 
-	objs, err := objectsFromReader(reader)
+	objs, err := objectsFromReader(reader, stdinName, lines)
+	if err == nil {
+		err = substituter.err()
+	}
 	if err != nil {
 		return objs, fmt.Errorf("fail to read from stream: %w", err)
 	}
@@ -0,0 +1,295 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const stdinName = "stdin"
+
+var (
+	// variableRegexp match the ${VAR} and ${VAR:-default} variables, a leading $ escape them
+	variableRegexp = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+	// templateFuncs is the restricted set of functions available to the templates
+	templateFuncs = template.FuncMap{
+		"default": func(defaultValue, value any) any {
+			if value == nil || value == "" {
+				return defaultValue
+			}
+			return value
+		},
+		"quote":   func(value any) string { return fmt.Sprintf("%q", fmt.Sprint(value)) },
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"trim":    strings.TrimSpace,
+		"replace": func(old, replacement, value string) string { return strings.ReplaceAll(value, old, replacement) },
+		"b64enc":  func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) },
+	}
+)
+
+// Substitution contains the sources of the variables that are expanded in the manifests before parsing them.
+// Variables are written as ${VAR} or ${VAR:-default}, and can be escaped as $${VAR}.
+type Substitution struct {
+	// Variables contains the values of the variables, they have precedence over all the other sources
+	Variables map[string]string
+	// ValuesFile is the path of a yaml file whose top level keys are used as variables, they have precedence
+	// over the environment
+	ValuesFile string
+	// UseEnvironment will resolve the variables with the environment of the process
+	UseEnvironment bool
+	// EnableTemplates will also render the manifests as Go templates after expanding the variables, using the
+	// variables as data, e.g. {{ .REPLICAS }}, and a restricted set of functions: default, quote, lower, upper,
+	// trim, replace and b64enc
+	EnableTemplates bool
+}
+
+// keep it to always check if UnresolvedVariableError implement correctly the error interface
+var _ error = UnresolvedVariableError{}
+
+// UnresolvedVariableError is used when a variable without a default value is not found in any source
+type UnresolvedVariableError struct {
+	File     string
+	Line     int
+	Variable string
+}
+
+// Error implements the error interface
+func (e UnresolvedVariableError) Error() string {
+	return fmt.Sprintf("%s:%d: unresolved variable %q", e.File, e.Line, e.Variable)
+}
+
+// substituter expand the content of the manifests and collect all the errors found, for reporting them together
+// at the end of the read. A nil substituter will not modify the manifests.
+type substituter struct {
+	values    map[string]any
+	templates bool
+	errs      []error
+}
+
+// newSubstituter return a substituter for substitution, or nil if substitution is nil
+func newSubstituter(substitution *Substitution) (*substituter, error) {
+	if substitution == nil {
+		return nil, nil
+	}
+
+	values := make(map[string]any)
+	if substitution.UseEnvironment {
+		for _, variable := range os.Environ() {
+			if name, value, found := strings.Cut(variable, "="); found {
+				values[name] = value
+			}
+		}
+	}
+
+	if substitution.ValuesFile != "" {
+		data, err := os.ReadFile(substitution.ValuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read values file: %w", err)
+		}
+
+		fileValues := make(map[string]any)
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("fail to parse values file %q: %w", substitution.ValuesFile, err)
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+
+	for name, value := range substitution.Variables {
+		values[name] = value
+	}
+
+	return &substituter{
+		values:    values,
+		templates: substitution.EnableTemplates,
+	}, nil
+}
+
+// expand return content with its variables expanded and, if enabled, rendered as a template; in case of errors
+// the content is returned as is and the errors are collected
+func (s *substituter) expand(name string, content []byte) []byte {
+	if s == nil {
+		return content
+	}
+
+	expanded, errs := s.expandVariables(name, content)
+	if len(errs) > 0 {
+		s.errs = append(s.errs, errs...)
+		return content
+	}
+
+	if !s.templates {
+		return expanded
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(expanded))
+	if err != nil {
+		s.errs = append(s.errs, err)
+		return content
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, s.values); err != nil {
+		s.errs = append(s.errs, err)
+		return content
+	}
+	return rendered.Bytes()
+}
+
+// expandVariables replace all the variables in content, returning an UnresolvedVariableError for every variable
+// without a value; the variables inside comments are left as they are
+func (s *substituter) expandVariables(name string, content []byte) ([]byte, []error) {
+	var errs []error
+	var expanded bytes.Buffer
+	last := 0
+	for _, match := range variableRegexp.FindAllSubmatchIndex(content, -1) {
+		expanded.Write(content[last:match[0]])
+		last = match[1]
+
+		if inComment(content, match[0]) {
+			expanded.Write(content[match[0]:match[1]])
+			continue
+		}
+
+		// an escaped variable is written without the escape character
+		if content[match[0]+1] == '$' {
+			expanded.Write(content[match[0]+1 : match[1]])
+			continue
+		}
+
+		variable := string(content[match[2]:match[3]])
+		value, found := s.values[variable]
+		switch {
+		case found:
+			fmt.Fprint(&expanded, value)
+		case match[4] != -1:
+			expanded.Write(content[match[6]:match[7]])
+		default:
+			errs = append(errs, UnresolvedVariableError{
+				File:     name,
+				Line:     bytes.Count(content[:match[0]], []byte("\n")) + 1,
+				Variable: variable,
+			})
+		}
+	}
+	expanded.Write(content[last:])
+
+	return expanded.Bytes(), errs
+}
+
+// inComment return true if the offset of content is inside a yaml comment, that start with a # at the beginning of
+// the line or after a blank, outside of quoted strings
+func inComment(content []byte, offset int) bool {
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	var quote byte
+	for idx := lineStart; idx < offset; idx++ {
+		char := content[idx]
+		tokenStart := idx == lineStart || strings.IndexByte(" \t[{,", content[idx-1]) != -1
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case (char == '"' || char == '\'') && tokenStart:
+			quote = char
+		case char == '#' && (idx == lineStart || content[idx-1] == ' ' || content[idx-1] == '\t'):
+			return true
+		}
+	}
+	return false
+}
+
+// reader return a reader with the expanded content of reader
+func (s *substituter) reader(name string, reader io.Reader) (io.Reader, error) {
+	if s == nil {
+		return reader, nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(s.expand(name, data)), nil
+}
+
+// fileSystem return fSys wrapped for expanding the content of the files read from it whose cleaned path is in files,
+// or of every file if files is nil
+func (s *substituter) fileSystem(fSys filesys.FileSystem, files sets.Set[string]) filesys.FileSystem {
+	if s == nil {
+		return fSys
+	}
+	return &substitutingFileSystem{FileSystem: fSys, substituter: s, files: files}
+}
+
+// err return all the errors collected during the expansion joined together, or nil
+func (s *substituter) err() error {
+	if s == nil {
+		return nil
+	}
+	return errors.Join(s.errs...)
+}
+
+// substitutingFileSystem is a filesys.FileSystem that expand the content of the files read from it
+type substitutingFileSystem struct {
+	filesys.FileSystem
+	substituter *substituter
+	files       sets.Set[string]
+}
+
+// expands return true if the content of the file at path must be expanded
+func (fs *substitutingFileSystem) expands(path string) bool {
+	return fs.files == nil || fs.files.Has(cleanedPath(fs.FileSystem, path))
+}
+
+// Open implement filesys.FileSystem, the returned file will read the expanded content
+func (fs *substitutingFileSystem) Open(path string) (filesys.File, error) {
+	file, err := fs.FileSystem.Open(path)
+	if err != nil || !fs.expands(path) {
+		return file, err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &bufferedFile{File: file, reader: bytes.NewReader(fs.substituter.expand(path, data))}, nil
+}
+
+// ReadFile implement filesys.FileSystem, returning the expanded content of the file
+func (fs *substitutingFileSystem) ReadFile(path string) ([]byte, error) {
+	data, err := fs.FileSystem.ReadFile(path)
+	if err != nil || !fs.expands(path) {
+		return data, err
+	}
+	return fs.substituter.expand(path, data), nil
+}
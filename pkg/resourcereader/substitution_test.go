@@ -0,0 +1,155 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcereader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstitution(t *testing.T) {
+	t.Parallel()
+
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("HOST: values.example.com\nREPLICAS: 2\n"), 0600))
+
+	testCases := map[string]struct {
+		substitution   *Substitution
+		content        string
+		expected       string
+		expectedErrors []error
+	}{
+		"nil substitution does not change the content": {
+			content:  "host: ${HOST}",
+			expected: "host: ${HOST}",
+		},
+		"expand variables with defaults and escapes": {
+			substitution: &Substitution{Variables: map[string]string{"HOST": "example.com"}},
+			content:      "host: ${HOST}\nport: ${PORT:-8080}\nscript: echo $${HOME} $HOST",
+			expected:     "host: example.com\nport: 8080\nscript: echo ${HOME} $HOST",
+		},
+		"variables have precedence over values file and environment": {
+			substitution: &Substitution{
+				Variables:      map[string]string{"HOST": "example.com"},
+				ValuesFile:     valuesFile,
+				UseEnvironment: true,
+			},
+			content:  "host: ${HOST}\nreplicas: ${REPLICAS}\npath: ${PATH}",
+			expected: "host: example.com\nreplicas: 2\npath: " + os.Getenv("PATH"),
+		},
+		"environment is not used by default": {
+			substitution: &Substitution{},
+			content:      "path: ${PATH}",
+			expected:     "path: ${PATH}",
+			expectedErrors: []error{
+				UnresolvedVariableError{File: "file.yaml", Line: 1, Variable: "PATH"},
+			},
+		},
+		"unresolved variables are all reported": {
+			substitution: &Substitution{Variables: map[string]string{"HOST": "example.com"}},
+			content:      "host: ${HOST}\nname: ${NAME}\nreplicas: ${REPLICAS}",
+			expected:     "host: ${HOST}\nname: ${NAME}\nreplicas: ${REPLICAS}",
+			expectedErrors: []error{
+				UnresolvedVariableError{File: "file.yaml", Line: 2, Variable: "NAME"},
+				UnresolvedVariableError{File: "file.yaml", Line: 3, Variable: "REPLICAS"},
+			},
+		},
+		"variables in comments are not expanded": {
+			substitution: &Substitution{Variables: map[string]string{"HOST": "example.com"}},
+			content:      "# ${UNSET}\nhost: ${HOST} # ${UNSET}\nurl: \"http://${HOST}/#${HOST}\"\nname: it's ${HOST} # ${UNSET}",
+			expected:     "# ${UNSET}\nhost: example.com # ${UNSET}\nurl: \"http://example.com/#example.com\"\nname: it's example.com # ${UNSET}",
+		},
+		"render templates after the variables": {
+			substitution: &Substitution{
+				Variables:       map[string]string{"HOST": "Example.com", "ENV": "prod"},
+				EnableTemplates: true,
+			},
+			content:  "host: {{ .HOST | lower | quote }}\nenv: ${ENV}\nreplicas: {{ if eq .ENV \"prod\" }}3{{ else }}1{{ end }}",
+			expected: "host: \"example.com\"\nenv: prod\nreplicas: 3",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			substituter, err := newSubstituter(testCase.substitution)
+			require.NoError(t, err)
+
+			expanded := substituter.expand("file.yaml", []byte(testCase.content))
+			assert.Equal(t, testCase.expected, string(expanded))
+			assert.Equal(t, errors.Join(testCase.expectedErrors...), substituter.err())
+		})
+	}
+}
+
+func TestSubstitutionTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	substituter, err := newSubstituter(&Substitution{EnableTemplates: true})
+	require.NoError(t, err)
+
+	substituter.expand("file.yaml", []byte("name: test\nhost: {{ .HOST }}"))
+	assert.ErrorContains(t, substituter.err(), "file.yaml:2:")
+	assert.ErrorContains(t, substituter.err(), "HOST")
+}
+
+func TestReadersSubstitution(t *testing.T) {
+	t.Parallel()
+
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ${NAME}\ndata:\n  host: ${HOST}\n"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(manifest), 0600))
+
+	testCases := map[string]struct {
+		reader        func(substitution *Substitution) Reader
+		errorLocation string
+	}{
+		"filepath reader": {
+			reader: func(substitution *Substitution) Reader {
+				return &FilepathReader{Path: dir, ReaderConfigs: ReaderConfigs{Substitution: substitution}}
+			},
+			errorLocation: filepath.Join(dir, "configmap.yaml") + ":6",
+		},
+		"stream reader": {
+			reader: func(substitution *Substitution) Reader {
+				return &StreamReader{Reader: strings.NewReader(manifest), ReaderConfigs: ReaderConfigs{Substitution: substitution}}
+			},
+			errorLocation: "stdin:6",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := testCase.reader(&Substitution{Variables: map[string]string{"NAME": "config", "HOST": "example.com"}}).Read()
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+			assert.Equal(t, "config", objects[0].GetName())
+			assert.Equal(t, map[string]interface{}{"host": "example.com"}, objects[0].Object["data"])
+
+			_, err = testCase.reader(&Substitution{Variables: map[string]string{"NAME": "config"}}).Read()
+			assert.ErrorContains(t, err, testCase.errorLocation+": unresolved variable \"HOST\"")
+		})
+	}
+}
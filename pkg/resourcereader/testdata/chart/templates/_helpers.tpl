@@ -0,0 +1,3 @@
+{{- define "test-chart.name" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end -}}
@@ -36,10 +36,23 @@ type ReaderConfigs struct {
 	Mapper           meta.RESTMapper
 	Namespace        string
 	EnforceNamespace bool
+
+	// Substitution if set is used for expanding the variables of the manifests before parsing them, it is not
+	// used for the templates of Helm charts
+	Substitution *Substitution
 }
 
 // Builder defines the interface for creating the correct Reader and cofigure it
 type Builder interface {
 	// ResourceReader return a Reader implementation based on the reader and path passed as arguments
 	ResourceReader(reader io.Reader, path string) (Reader, error)
+	// WithKustomize make the paths of directories containing a kustomization file to be built with kustomize,
+	// instead of reading the files that they contain
+	WithKustomize() Builder
+	// WithoutRemoteBases make the kustomizations fail to build if they reference remote bases or files
+	WithoutRemoteBases() Builder
+	// WithSubstitution set the Substitution used by the readers for expanding the variables of the manifests
+	WithSubstitution(substitution *Substitution) Builder
+	// WithFileFilter set the FileFilter used for selecting the files to read from directories
+	WithFileFilter(filter FileFilter) Builder
 }
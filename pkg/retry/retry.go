@@ -0,0 +1,191 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultInitialDelay = 500 * time.Millisecond
+	defaultMaxDelay     = 30 * time.Second
+	defaultJitter       = 0.2
+)
+
+// Policy describe how many times and how often an operation that has failed for a transient error is tried again.
+// The delay between two attempts start from InitialDelay and is doubled after every failure up to MaxDelay, if the
+// api-server asks to wait longer with a Retry-After header its delay is used instead.
+// A nil Policy will execute every operation only once.
+type Policy struct {
+	// MaxAttempts is the maximum number of times an operation is executed, values lower than 2 disable the retries
+	MaxAttempts int
+	// InitialDelay is the time waited before the first retry
+	InitialDelay time.Duration
+	// MaxDelay is the maximum time waited between two attempts, if zero the delay is not limited
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay that is randomly added to it, for spreading the retries of
+	// concurrent operations, 0.2 will add up to 20% of the delay
+	Jitter float64
+}
+
+// DefaultPolicy return a Policy that try every operation up to 5 times, starting with a delay of half a second
+// and waiting at most 30 seconds between two attempts
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MaxAttempts:  defaultMaxAttempts,
+		InitialDelay: defaultInitialDelay,
+		MaxDelay:     defaultMaxDelay,
+		Jitter:       defaultJitter,
+	}
+}
+
+// NotifyFunc is called before waiting for every retry, with the description of the failed attempt
+type NotifyFunc func(info event.RetryInfo)
+
+type notifyFuncKey struct{}
+
+// ContextWithNotifyFunc return a copy of ctx that will notify to notify the retries of the operations executed with it
+func ContextWithNotifyFunc(ctx context.Context, notify NotifyFunc) context.Context {
+	return context.WithValue(ctx, notifyFuncKey{}, notify)
+}
+
+// notify forward info to the NotifyFunc contained in ctx, if any
+func notify(ctx context.Context, info event.RetryInfo) {
+	if notify, ok := ctx.Value(notifyFuncKey{}).(NotifyFunc); ok && notify != nil {
+		notify(info)
+	}
+}
+
+// Do execute fn until it succeeds, it returns an error that cannot be retried, the attempts of the policy have
+// been exhausted or ctx is done, returning the last error received. Every retry is notified to the NotifyFunc
+// of ctx described by operation.
+func (p *Policy) Do(ctx context.Context, operation string, fn func() error) error {
+	maxAttempts := 1
+	if p != nil {
+		maxAttempts = max(p.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxAttempts || !IsRetriable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := p.delay(attempt, err)
+		notify(ctx, event.RetryInfo{
+			Operation:   operation,
+			Attempt:     attempt,
+			MaxAttempts: maxAttempts,
+			Delay:       delay,
+			Error:       err,
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay return the time to wait after attempt has failed with err
+func (p *Policy) delay(attempt int, err error) time.Duration {
+	delay := p.InitialDelay
+	for range attempt - 1 {
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 {
+		delay = min(delay, p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration(rand.Float64() * p.Jitter * float64(delay)) //nolint:gosec // jitter does not need a secure random source
+	}
+
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		delay = max(delay, time.Duration(seconds)*time.Second)
+	}
+
+	return delay
+}
+
+// IsRetriable return true if err is a transient error returned by the api-server or by the network that can
+// disappear trying again the same request later: throttling, timeouts, unavailable servers, connection resets and
+// conflicts on the resourceVersion of an object. Other server errors, like the internal errors used for reporting
+// failed admission webhooks, are deterministic and are not retried.
+func IsRetriable(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return false
+	case apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err):
+		return true
+	case apierrors.IsServiceUnavailable(err) || hasStatusCode(err, http.StatusServiceUnavailable, http.StatusGatewayTimeout):
+		return true
+	case apierrors.IsConflict(err):
+		return !hasFieldManagerConflicts(err)
+	case utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err):
+		return true
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// hasStatusCode return true if err is an api error with one of the codes
+func hasStatusCode(err error, codes ...int32) bool {
+	var statusErr apierrors.APIStatus
+	return errors.As(err, &statusErr) && slices.Contains(codes, statusErr.Status().Code)
+}
+
+// hasFieldManagerConflicts return true if err is a conflict for fields owned by other field managers, that will
+// not be solved trying again
+func hasFieldManagerConflicts(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return false
+	}
+
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			return true
+		}
+	}
+	return false
+}
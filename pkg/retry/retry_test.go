@@ -0,0 +1,227 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+var configMapGR = schema.GroupResource{Resource: "configmaps"}
+
+func TestIsRetriable(t *testing.T) {
+	t.Parallel()
+
+	fieldManagerConflict := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "operator"`, Field: ".data.key"},
+	}, "Apply failed with 1 conflict")
+
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil error": {
+			expected: false,
+		},
+		"generic error": {
+			err:      errors.New("generic error"),
+			expected: false,
+		},
+		"too many requests": {
+			err:      apierrors.NewTooManyRequests("too many requests", 1),
+			expected: true,
+		},
+		"server timeout": {
+			err:      apierrors.NewServerTimeout(configMapGR, "update", 1),
+			expected: true,
+		},
+		"service unavailable": {
+			err:      apierrors.NewServiceUnavailable("shutting down"),
+			expected: true,
+		},
+		"gateway timeout": {
+			err:      apierrors.NewGenericServerResponse(http.StatusGatewayTimeout, "get", configMapGR, "inventory", "", 0, false),
+			expected: true,
+		},
+		"internal error": {
+			err:      apierrors.NewInternalError(errors.New("etcd failure")),
+			expected: false,
+		},
+		"admission webhook denial": {
+			err:      apierrors.NewGenericServerResponse(http.StatusInternalServerError, "patch", configMapGR, "inventory", `admission webhook "validate.example.com" denied the request`, 0, false),
+			expected: false,
+		},
+		"resource version conflict": {
+			err:      apierrors.NewConflict(configMapGR, "inventory", errors.New("the object has been modified")),
+			expected: true,
+		},
+		"field manager conflict": {
+			err:      fieldManagerConflict,
+			expected: false,
+		},
+		"unexpected EOF": {
+			err:      fmt.Errorf("request failed: %w", io.ErrUnexpectedEOF),
+			expected: true,
+		},
+		"not found": {
+			err:      apierrors.NewNotFound(configMapGR, "inventory"),
+			expected: false,
+		},
+		"forbidden": {
+			err:      apierrors.NewForbidden(configMapGR, "inventory", errors.New("forbidden")),
+			expected: false,
+		},
+		"context canceled": {
+			err:      context.Canceled,
+			expected: false,
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, IsRetriable(testCase.err))
+		})
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	t.Parallel()
+
+	transientErr := apierrors.NewServiceUnavailable("shutting down")
+	permanentErr := apierrors.NewNotFound(configMapGR, "inventory")
+
+	tests := map[string]struct {
+		policy           *Policy
+		errors           []error
+		expectedErr      error
+		expectedAttempts int
+		expectedRetries  int
+	}{
+		"nil policy execute the operation once": {
+			errors:           []error{transientErr, nil},
+			expectedErr:      transientErr,
+			expectedAttempts: 1,
+		},
+		"successful operation is not retried": {
+			policy:           &Policy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			errors:           []error{nil},
+			expectedAttempts: 1,
+		},
+		"transient errors are retried": {
+			policy:           &Policy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			errors:           []error{transientErr, transientErr, nil},
+			expectedAttempts: 3,
+			expectedRetries:  2,
+		},
+		"attempts are exhausted": {
+			policy:           &Policy{MaxAttempts: 2, InitialDelay: time.Millisecond},
+			errors:           []error{transientErr, transientErr, nil},
+			expectedErr:      transientErr,
+			expectedAttempts: 2,
+			expectedRetries:  1,
+		},
+		"permanent errors are not retried": {
+			policy:           &Policy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			errors:           []error{permanentErr, nil},
+			expectedErr:      permanentErr,
+			expectedAttempts: 1,
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var retries []event.RetryInfo
+			ctx := ContextWithNotifyFunc(t.Context(), func(info event.RetryInfo) {
+				retries = append(retries, info)
+			})
+
+			attempts := 0
+			err := testCase.policy.Do(ctx, "test", func() error {
+				err := testCase.errors[attempts]
+				attempts++
+				return err
+			})
+
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedAttempts, attempts)
+			require.Len(t, retries, testCase.expectedRetries)
+			for idx, info := range retries {
+				assert.Equal(t, "test", info.Operation)
+				assert.Equal(t, idx+1, info.Attempt)
+				assert.Equal(t, testCase.policy.MaxAttempts, info.MaxAttempts)
+				assert.Equal(t, transientErr, info.Error)
+			}
+		})
+	}
+}
+
+func TestPolicyDoStopOnContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	policy := &Policy{MaxAttempts: 3, InitialDelay: time.Hour}
+	transientErr := apierrors.NewServiceUnavailable("shutting down")
+
+	attempts := 0
+	ctx = ContextWithNotifyFunc(ctx, func(event.RetryInfo) { cancel() })
+	err := policy.Do(ctx, "test", func() error {
+		attempts++
+		return transientErr
+	})
+
+	assert.Equal(t, transientErr, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := &Policy{MaxAttempts: 10, InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	transientErr := apierrors.NewServiceUnavailable("shutting down")
+
+	assert.Equal(t, time.Second, policy.delay(1, transientErr))
+	assert.Equal(t, 2*time.Second, policy.delay(2, transientErr))
+	assert.Equal(t, 4*time.Second, policy.delay(3, transientErr))
+	assert.Equal(t, 5*time.Second, policy.delay(4, transientErr))
+	assert.Equal(t, 5*time.Second, policy.delay(9, transientErr))
+
+	// the delay asked by the api-server is honoured even if longer than the maximum one
+	throttledErr := apierrors.NewTooManyRequests("too many requests", 10)
+	assert.Equal(t, 10*time.Second, policy.delay(1, throttledErr))
+
+	policy.Jitter = 0.5
+	for range 10 {
+		delay := policy.delay(1, transientErr)
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+}
@@ -19,15 +19,18 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
 )
 
 var _ State = &FakeState{}
 
 type FakeState struct {
-	Context    context.Context
-	SentEvents []event.Event
+	Context       context.Context
+	SentEvents    []event.Event
+	FailedObjects sets.Set[resource.ObjectMetadata]
 }
 
 func (s *FakeState) GetContext() context.Context {
@@ -41,3 +44,11 @@ func (s *FakeState) SendEvent(event event.Event) {
 func (s *FakeState) SkipWaitCurrentStatus(*unstructured.Unstructured) bool {
 	return false
 }
+
+func (s *FakeState) IsFailed(id resource.ObjectMetadata) bool {
+	return s.FailedObjects.Has(id)
+}
+
+func (s *FakeState) HasFailures() bool {
+	return s.FailedObjects.Len() > 0
+}
@@ -15,6 +15,21 @@
 
 package runner
 
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/telemetry"
+)
+
+// DefaultFinalizerGracePeriod is the time given to the Finalizer tasks for completing their work after the
+// context of the run is done
+const DefaultFinalizerGracePeriod = 30 * time.Second
+
 // TaskRunner provides abstraction for a TaskRunner implementation
 type TaskRunner interface {
 	// RunWithQueue will start to execute all the tasks that will be found in the channel
@@ -23,19 +38,30 @@ type TaskRunner interface {
 
 // NewTaskRunner return an implementation of TaskRunner
 func NewTaskRunner() TaskRunner {
-	return &taskRunner{}
+	return &taskRunner{
+		gracePeriod: DefaultFinalizerGracePeriod,
+		now:         time.Now,
+	}
 }
 
-type taskRunner struct{}
+type taskRunner struct {
+	gracePeriod time.Duration
+	now         func() time.Time
+}
 
 func (r *taskRunner) RunWithQueue(state State, taskQueue <-chan Task) error {
+	// groups keep the number of tasks of every kind already run, for setting the group index in their events
+	groups := make(map[string]int)
+	state = &timestampState{State: state, now: r.now}
 	ctx := state.GetContext()
 	done := ctx.Done()
 
 	for {
 		select {
-		// if the context is ended or cancelled return the error if present (always nil if done with success)
+		// if the context is ended or cancelled run the remaining finalizers and return the error if present
+		// (always nil if done with success)
 		case <-done:
+			r.runFinalizers(state, taskQueue, groups)
 			return ctx.Err()
 
 		// cycle on task in the queue until they are there
@@ -44,7 +70,95 @@ func (r *taskRunner) RunWithQueue(state State, taskQueue <-chan Task) error {
 				return nil
 			}
 
-			currentTask.Run(state)
+			// the select can pick a task even if the context is already done, treat it as a remaining task
+			if ctx.Err() != nil {
+				r.runFinalizer(state, currentTask, groups)
+				r.runFinalizers(state, taskQueue, groups)
+				return ctx.Err()
+			}
+
+			r.runTask(state, currentTask, groups)
+		}
+	}
+}
+
+// runTask will run task sending the events for its start and end, groups is updated with the kind of the task
+func (r *taskRunner) runTask(state State, task Task, groups map[string]int) {
+	info := event.TaskInfo{
+		Kind: fmt.Sprintf("%T", task),
+	}
+	if describer, ok := task.(Describer); ok {
+		info.Kind = describer.Kind()
+		info.Objects = describer.ObjectsCount()
+	}
+	info.Group = groups[info.Kind]
+	groups[info.Kind]++
+
+	ctx, span := telemetry.StartSpan(state.GetContext(), "task "+info.Kind,
+		attribute.String("jpl.task.kind", info.Kind),
+		attribute.Int("jpl.task.group", info.Group),
+		attribute.Int("jpl.task.objects", info.Objects),
+	)
+	defer span.End()
+
+	start := r.now()
+	info.Status = event.StatusPending
+	state.SendEvent(event.Event{Type: event.TypeTask, Timestamp: start, TaskInfo: info})
+
+	task.Run(&contextState{State: state, context: ctx})
+
+	end := r.now()
+	info.Status = event.StatusSuccessful
+	info.Duration = end.Sub(start)
+	state.SendEvent(event.Event{Type: event.TypeTask, Timestamp: end, TaskInfo: info})
+}
+
+// runFinalizers will run all the Finalizer tasks that are still in the queue without waiting for new ones
+func (r *taskRunner) runFinalizers(state State, taskQueue <-chan Task, groups map[string]int) {
+	for {
+		select {
+		case currentTask, open := <-taskQueue:
+			if !open {
+				return
+			}
+			r.runFinalizer(state, currentTask, groups)
+		default:
+			return
 		}
 	}
 }
+
+// runFinalizer will run task only if is a Finalizer, using a new context that will expire after the grace period
+func (r *taskRunner) runFinalizer(state State, task Task, groups map[string]int) {
+	if finalizer, ok := task.(Finalizer); !ok || !finalizer.IsFinalizer() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(state.GetContext()), r.gracePeriod)
+	defer cancel()
+	r.runTask(&contextState{State: state, context: ctx}, task, groups)
+}
+
+// contextState wrap the State of the run for replacing its context, like the grace one of the finalizers or
+// the one containing the span of the task
+type contextState struct {
+	State
+	context context.Context
+}
+
+func (s *contextState) GetContext() context.Context {
+	return s.context
+}
+
+// timestampState wrap the State of the run for setting the time of the events sent by the tasks
+type timestampState struct {
+	State
+	now func() time.Time
+}
+
+func (s *timestampState) SendEvent(e event.Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = s.now()
+	}
+	s.State.SendEvent(e)
+}
@@ -18,6 +18,7 @@ package runner
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -41,6 +42,54 @@ func (t *fakeTask) Run(state State) {
 
 func (t *fakeTask) Cancel() {}
 
+type fakeDescriberTask struct {
+	fakeTask
+	kind    string
+	objects int
+}
+
+func (t *fakeDescriberTask) Kind() string {
+	return t.kind
+}
+
+func (t *fakeDescriberTask) ObjectsCount() int {
+	return t.objects
+}
+
+type fakeFinalizerTask struct {
+	contextErr error
+	run        bool
+}
+
+func (t *fakeFinalizerTask) Run(state State) {
+	t.run = true
+	t.contextErr = state.GetContext().Err()
+	state.SendEvent(event.Event{Type: event.TypeInventory})
+}
+
+func (t *fakeFinalizerTask) IsFinalizer() bool {
+	return true
+}
+
+var testTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func fixedClock() time.Time {
+	return testTime
+}
+
+// taskEvents return the events expected for a task with kind and group wrapping the events sent by it
+func taskEvents(kind string, group int, events ...event.Event) []event.Event {
+	info := event.TaskInfo{Kind: kind, Group: group, Status: event.StatusPending}
+	taskEvents := []event.Event{{Type: event.TypeTask, Timestamp: testTime, TaskInfo: info}}
+	for _, e := range events {
+		e.Timestamp = testTime
+		taskEvents = append(taskEvents, e)
+	}
+
+	info.Status = event.StatusSuccessful
+	return append(taskEvents, event.Event{Type: event.TypeTask, Timestamp: testTime, TaskInfo: info})
+}
+
 func TestNewTaskRunner(t *testing.T) {
 	t.Parallel()
 	assert.NotNil(t, NewTaskRunner())
@@ -62,11 +111,11 @@ func TestRunWithQueue(t *testing.T) {
 				defer close(queue)
 				return queue
 			},
-			expectedEvents: []event.Event{
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}},
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}},
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}},
-			},
+			expectedEvents: slices.Concat(
+				taskEvents("*runner.fakeTask", 0, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}}),
+				taskEvents("*runner.fakeTask", 1, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}}),
+				taskEvents("*runner.fakeTask", 2, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}}),
+			),
 		},
 		"run nil queue": {
 			taskQueue:      func() chan Task { return nil },
@@ -86,11 +135,11 @@ func TestRunWithQueue(t *testing.T) {
 				queue <- &fakeTask{}
 				return queue
 			},
-			expectedEvents: []event.Event{
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}},
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Error: errors.New("error in task")}},
-				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}},
-			},
+			expectedEvents: slices.Concat(
+				taskEvents("*runner.fakeTask", 0, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}}),
+				taskEvents("*runner.fakeTask", 1, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Error: errors.New("error in task")}}),
+				taskEvents("*runner.fakeTask", 2, event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{}}),
+			),
 		},
 	}
 
@@ -102,7 +151,7 @@ func TestRunWithQueue(t *testing.T) {
 			defer cancel()
 			state := &FakeState{Context: withTimeout}
 
-			r := &taskRunner{}
+			r := &taskRunner{now: fixedClock}
 			r.RunWithQueue(state, testCase.taskQueue())
 			assert.Equal(t, testCase.expectedEvents, state.SentEvents)
 		})
@@ -114,7 +163,7 @@ func TestCancelQueue(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(t.Context())
 	state := &FakeState{Context: ctx}
-	r := &taskRunner{}
+	r := &taskRunner{now: fixedClock}
 	taskQueue := func() chan Task {
 		queue := make(chan Task, 3)
 		queue <- &fakeTask{}
@@ -128,3 +177,64 @@ func TestCancelQueue(t *testing.T) {
 	err := r.RunWithQueue(state, taskQueue())
 	assert.ErrorContains(t, err, "context canceled")
 }
+
+func TestFinalizersAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	state := &FakeState{Context: ctx}
+	r := &taskRunner{gracePeriod: time.Second, now: fixedClock}
+	finalizer := &fakeFinalizerTask{}
+	queue := make(chan Task, 3)
+	queue <- &fakeTask{}
+	queue <- finalizer
+	queue <- &fakeTask{}
+	close(queue)
+
+	cancel()
+
+	err := r.RunWithQueue(state, queue)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, finalizer.run)
+	assert.NoError(t, finalizer.contextErr)
+	assert.Equal(t, taskEvents("*runner.fakeFinalizerTask", 0, event.Event{Type: event.TypeInventory}), state.SentEvents)
+}
+
+func TestTaskLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	// every call to the clock will advance it by one second
+	calls := 0
+	clock := func() time.Time {
+		calls++
+		return testTime.Add(time.Duration(calls) * time.Second)
+	}
+
+	state := &FakeState{Context: t.Context()}
+	r := &taskRunner{now: clock}
+	queue := make(chan Task, 3)
+	queue <- &fakeDescriberTask{kind: "apply", objects: 2}
+	queue <- &fakeDescriberTask{kind: "wait", objects: 2}
+	queue <- &fakeDescriberTask{kind: "apply", objects: 1}
+	close(queue)
+
+	err := r.RunWithQueue(state, queue)
+	assert.NoError(t, err)
+
+	taskInfos := make([]event.TaskInfo, 0)
+	for _, e := range state.SentEvents {
+		assert.False(t, e.Timestamp.IsZero())
+		if e.Type == event.TypeTask {
+			taskInfos = append(taskInfos, e.TaskInfo)
+		}
+	}
+
+	assert.Equal(t, []event.TaskInfo{
+		{Kind: "apply", Group: 0, Objects: 2, Status: event.StatusPending},
+		{Kind: "apply", Group: 0, Objects: 2, Status: event.StatusSuccessful, Duration: 2 * time.Second},
+		{Kind: "wait", Group: 0, Objects: 2, Status: event.StatusPending},
+		{Kind: "wait", Group: 0, Objects: 2, Status: event.StatusSuccessful, Duration: 2 * time.Second},
+		{Kind: "apply", Group: 1, Objects: 1, Status: event.StatusPending},
+		{Kind: "apply", Group: 1, Objects: 1, Status: event.StatusSuccessful, Duration: 2 * time.Second},
+	}, taskInfos)
+}
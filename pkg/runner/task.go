@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/resource"
 )
 
 // Task provides abstractions that a Task must implement to be able to be used by a Runner
@@ -30,6 +31,21 @@ type Task interface {
 	Run(State)
 }
 
+// Finalizer can be implemented by a Task that must be run even if the context of the run is done before reaching
+// it in the queue, for example for saving the progress made until that moment
+type Finalizer interface {
+	// IsFinalizer return true if the Task must be run after the end of the context of the run
+	IsFinalizer() bool
+}
+
+// Describer can be implemented by a Task for adding details about its work to the task lifecycle events
+type Describer interface {
+	// Kind return the type of work done by the Task
+	Kind() string
+	// ObjectsCount return the number of objects handled by the Task
+	ObjectsCount() int
+}
+
 // State encapsulate the state of the run for sharing data between different tasks execution
 type State interface {
 	// GetContext return the Context where to execute task
@@ -41,4 +57,10 @@ type State interface {
 	// SkipWaitCurrentStatus return if the object has to be skipped for waiting.
 	// Return true if for some reasons is knowns that the object cannot be watched
 	SkipWaitCurrentStatus(*unstructured.Unstructured) bool
+
+	// IsFailed return if the object has failed to be applied during the current run
+	IsFailed(resource.ObjectMetadata) bool
+
+	// HasFailures return if any object has failed to be applied during the current run
+	HasFailures() bool
 }
@@ -17,8 +17,13 @@ package task
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -28,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/util/csaupgrade"
 	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
@@ -35,20 +41,41 @@ import (
 	"github.com/mia-platform/jpl/pkg/client/cache"
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/filter"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/poller"
 	pkgresource "github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 	"github.com/mia-platform/jpl/pkg/util"
 )
 
 const (
 	maxPatchRetry = 5
+	// removalPollInterval is the time between the checks of an object to replace when no DeletionPoller is available
+	removalPollInterval = 500 * time.Millisecond
 
-	warningMigrationPatchFailed      = "WARNING: server rejected managed fields migration to Server-Side Apply. This is non-fatal and will be retried next time you apply. Error: %[1]s\n"
-	warningChangesOnDeletingResource = "WARNING: try to change %[1]s resource which is currently being deleted.\n"
-	warningMigrationReapplyFailed    = "WARNING: failed to re-apply configuration after performing Server-Side Apply migration. This is non-fatal and will be retried next time you apply. Error: %[1]s\n"
+	warningMigrationPatchFailed      = "server rejected managed fields migration to Server-Side Apply. This is non-fatal and will be retried next time you apply. Error: %[1]s"
+	warningChangesOnDeletingResource = "try to change %[1]s resource which is currently being deleted"
+	warningMigrationReapplyFailed    = "failed to re-apply configuration after performing Server-Side Apply migration. This is non-fatal and will be retried next time you apply. Error: %[1]s"
 )
 
 var (
+	// ErrFailFast is used for skipping objects after a failure when the fail fast option is enabled
+	ErrFailFast = errors.New("skipped for a previous failure")
+	// ErrNotRemoved is used when an object that must be replaced has not been removed from the remote server
+	ErrNotRemoved = errors.New("object has not been removed from the remote server")
+
+	// errNoRemoteGetter is used when the remote state of an object is needed but no RemoteGetter is available
+	errNoRemoteGetter = errors.New("remote state of the object is not available")
+
+	// immutableFieldMessages contains the messages used by the api-server for rejecting changes to immutable fields
+	immutableFieldMessages = []string{
+		"field is immutable",
+		"may not change once set",
+		"updates to statefulset spec for fields other than",
+	}
+
 	lastAppliedAnnotationFieldPath = fieldpath.NewSet(
 		fieldpath.MakePathOrDie(
 			"metadata", "annotations",
@@ -61,91 +88,524 @@ type InfoFetcher func(*unstructured.Unstructured) (*resource.Info, error)
 
 // keep it to always check if ApplyTask implement correctly the Task interface
 var _ runner.Task = &ApplyTask{}
+var _ runner.Describer = &ApplyTask{}
 
 // ApplyTask will apply the Objects to a remote api-server with the server-side config of kubectl
 type ApplyTask struct {
 	DryRun       bool
 	FieldManager string
 
+	// Graph if set is used for skipping the objects that depends on other objects that have failed to be applied
+	Graph *pkgresource.DependencyGraph
+	// FailFast will skip all the Objects if an object has already failed to be applied during the run
+	FailFast bool
+
+	// DisableForceConflicts will make the apply fail if the object has fields managed by other field managers
+	// instead of taking their ownership
+	DisableForceConflicts bool
+
+	// Concurrency is the maximum number of Objects that will be applied at the same time, values lower than 1
+	// will apply them one at a time
+	Concurrency int
+
+	// InventoryID if set will be stamped on every object as its owner, and objects already owned by
+	// another inventory will be applied only if allowed by the AdoptionPolicy
+	InventoryID    string
+	AdoptionPolicy inventory.AdoptionPolicy
+
+	// ReplaceOnImmutable will delete and create again the Objects that fail to be applied for changes to immutable
+	// fields, objects annotated with resource.ReplaceAnnotation are replaced even if it is false
+	ReplaceOnImmutable bool
+	// Poller is used for waiting the removal of the objects to replace, if not set they are created again as soon
+	// as the deletion request has been accepted. If it implements poller.StatusChecker it is also used for
+	// marking as current the unchanged objects that are already ready
+	Poller poller.StatusPoller
+
+	// Metrics if set is updated with the results and the api-server latency of the Objects
+	Metrics *telemetry.Metrics
+	// RetryPolicy if set is used for retrying the requests that have failed for transient errors
+	RetryPolicy *retry.Policy
+
 	RemoteGetter cache.RemoteResourceGetter
 	Objects      []*unstructured.Unstructured
 	Filters      []filter.Interface
 	InfoFetcher  InfoFetcher
 }
 
+// Kind implement the runner.Describer interface
+func (t *ApplyTask) Kind() string {
+	return "apply"
+}
+
+// ObjectsCount implement the runner.Describer interface
+func (t *ApplyTask) ObjectsCount() int {
+	return len(t.Objects)
+}
+
 // Run implement the runner.Task interface
 func (t *ApplyTask) Run(state runner.State) {
 	ctx := state.GetContext()
 
-	for _, obj := range t.Objects {
-		filteredObj := false
-		for _, filter := range t.Filters {
-			filtered, filterError := filter.Filter(obj, t.RemoteGetter)
-			if filterError != nil {
-				state.SendEvent(applyEvent(event.StatusFailed, obj, filterError))
-				filteredObj = true
-				break
-			}
+	// objects are applied concurrently, but their events are collected and sent following the Objects order
+	// to keep them consistent between runs and to not access the state from multiple goroutines
+	results := make([]chan applyResult, len(t.Objects))
+	for idx := range results {
+		results[idx] = make(chan applyResult, 1)
+	}
 
-			if filtered {
-				state.SendEvent(skippedEvent(obj))
-				filteredObj = true
-				break
-			}
+	// check the previous failures before starting to apply the objects of this group, to read the state only
+	// from the current goroutine
+	skipErrors := make([]error, len(t.Objects))
+	for idx, obj := range t.Objects {
+		skipErrors[idx] = t.skipError(state, obj)
+	}
+
+	var aborted atomic.Bool
+	go func() {
+		semaphore := make(chan struct{}, max(t.Concurrency, 1))
+		for idx, obj := range t.Objects {
+			semaphore <- struct{}{}
+			go func() {
+				defer func() { <-semaphore }()
+				if aborted.Load() {
+					results[idx] <- applyResult{abort: true}
+					return
+				}
+
+				if skipErrors[idx] != nil {
+					t.Metrics.ObserveObject(telemetry.OperationApply, event.StatusSkipped)
+					results[idx] <- applyResult{events: []event.Event{skippedEvent(obj, skipErrors[idx])}}
+					return
+				}
+
+				result := t.observeObject(ctx, obj)
+				if result.abort {
+					aborted.Store(true)
+				}
+				results[idx] <- result
+			}()
 		}
+	}()
 
-		if filteredObj {
-			continue
+	for _, resultCh := range results {
+		result := <-resultCh
+		for _, e := range result.events {
+			state.SendEvent(e)
 		}
 
-		state.SendEvent(applyEvent(event.StatusPending, obj, nil))
-		info, err := t.InfoFetcher(obj)
-		if err != nil {
-			state.SendEvent(applyEvent(event.StatusFailed, obj, err))
-			continue
+		if result.abort {
+			break
+		}
+	}
+}
+
+// applyResult contains the events generated applying an object, and if the remaining objects must not be applied
+type applyResult struct {
+	events []event.Event
+	abort  bool
+}
+
+// skipError return the reason why obj must not be applied because of the failures happened during the run,
+// or nil if it can be applied
+func (t *ApplyTask) skipError(state runner.State, obj *unstructured.Unstructured) error {
+	if t.FailFast && state.HasFailures() {
+		return ErrFailFast
+	}
+
+	if t.Graph == nil {
+		return nil
+	}
+
+	for _, dependency := range t.Graph.Dependencies(obj) {
+		dependencyID := pkgresource.ObjectMetadataFromUnstructured(dependency)
+		if state.IsFailed(dependencyID) {
+			return pkgresource.DependencyFailedError{Dependency: dependencyID}
+		}
+	}
+
+	return nil
+}
+
+// observeObject return the result of processObject for obj, tracing it in its own span and updating the metrics
+// with its final status
+func (t *ApplyTask) observeObject(ctx context.Context, obj *unstructured.Unstructured) applyResult {
+	ctx, span := telemetry.StartSpan(ctx, "apply object", telemetry.ObjectAttributes(obj)...)
+	var notices []event.Event
+	ctx = util.ContextWithWarningFunc(ctx, func(message string) {
+		notices = append(notices, warningEvent(obj, message))
+	})
+	ctx = retry.ContextWithNotifyFunc(ctx, func(info event.RetryInfo) {
+		notices = append(notices, retryEvent(obj, info))
+	})
+	result := t.processObject(ctx, obj)
+
+	last := len(result.events) - 1
+	finalInfo := result.events[last].ApplyInfo
+	telemetry.EndSpan(span, finalInfo.Error)
+	t.Metrics.ObserveObject(telemetry.OperationApply, finalInfo.Status)
+
+	// the warnings and the retries are sent just before the final event of the object
+	result.events = slices.Concat(result.events[:last], notices, result.events[last:])
+	return result
+}
+
+// processObject return the result of applying obj to the remote server
+func (t *ApplyTask) processObject(ctx context.Context, obj *unstructured.Unstructured) applyResult {
+	for _, filter := range t.Filters {
+		filtered, filterError := filter.Filter(obj, t.RemoteGetter)
+		if filterError != nil {
+			return applyResult{events: []event.Event{applyEvent(event.StatusFailed, obj, filterError)}}
+		}
+
+		if filtered {
+			return applyResult{events: []event.Event{skippedEvent(obj, nil)}}
+		}
+	}
+
+	events := []event.Event{applyEvent(event.StatusPending, obj, nil)}
+	// the live object is used for knowing what the apply has done, failing to get it is fatal only when it is
+	// needed for checking the ownership of the object
+	live, liveErr := t.liveObject(ctx, obj)
+	if liveErr != nil && t.InventoryID != "" {
+		return applyResult{events: append(events, applyEvent(event.StatusFailed, obj, liveErr))}
+	}
+
+	objToApply, err := ownedObject(live, obj, t.InventoryID, t.AdoptionPolicy)
+	if err != nil {
+		return applyResult{events: append(events, applyEvent(event.StatusFailed, obj, err))}
+	}
+
+	info, err := t.InfoFetcher(objToApply)
+	if err != nil {
+		return applyResult{events: append(events, applyEvent(event.StatusFailed, obj, err))}
+	}
+
+	start := time.Now()
+	err = t.RetryPolicy.Do(ctx, "apply", func() error {
+		return applyObject(ctx, info, t.DryRun, !t.DisableForceConflicts, t.FieldManager)
+	})
+	t.Metrics.ObserveRequest(telemetry.OperationApply, time.Since(start))
+	if err != nil {
+		if isImmutableFieldError(err) && (t.ReplaceOnImmutable || pkgresource.IsReplaceOnImmutable(obj)) {
+			return applyResult{events: append(events, t.replaceObject(ctx, obj, info)...)}
+		}
+
+		failedEvent := applyEvent(event.StatusFailed, obj, err)
+		failedEvent.ApplyInfo.Conflicts = conflictsFromError(err)
+		// if the error returned is unsupported media, it means that api-server don't support server side apply
+		// and so every other requests will fail as well. Bail out
+		return applyResult{
+			events: append(events, failedEvent),
+			abort:  apierrors.IsUnsupportedMediaType(err),
+		}
+	}
+
+	successEvent := applyEvent(event.StatusSuccessful, obj, nil)
+	if liveErr == nil {
+		t.setApplyOperation(&successEvent.ApplyInfo, live, info.Object)
+	}
+	return applyResult{events: append(events, successEvent)}
+}
+
+// liveObject return the remote counterpart of obj, or nil if it does not exist
+func (t *ApplyTask) liveObject(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if t.RemoteGetter == nil {
+		return nil, errNoRemoteGetter
+	}
+
+	return t.RemoteGetter.Get(ctx, pkgresource.ObjectMetadataFromUnstructured(obj))
+}
+
+// setApplyOperation set in info the operation done by the api-server comparing the live object with the applied
+// one returned by the server, unchanged objects are also marked as current if their status is already ready
+func (t *ApplyTask) setApplyOperation(info *event.ApplyInfo, live *unstructured.Unstructured, applied runtime.Object) {
+	appliedObj, err := toUnstructured(applied)
+	if err != nil {
+		return
+	}
+
+	info.Operation = applyOperation(live, appliedObj, t.DryRun)
+	if info.Operation != event.ApplyUnchanged {
+		return
+	}
+
+	if checker, ok := t.Poller.(poller.StatusChecker); ok {
+		result, err := checker.Status(appliedObj)
+		info.Current = err == nil && result.Status == poller.StatusCurrent
+	}
+}
+
+// applyOperation return the operation done by the api-server for creating applied from live. In dry run the
+// resourceVersion of the object is not updated, so the two objects are compared without the server fields
+func applyOperation(live, applied *unstructured.Unstructured, dryRun bool) event.ApplyOperation {
+	if live == nil {
+		return event.ApplyCreated
+	}
+
+	if dryRun {
+		diff, err := objectsDiff(live, applied)
+		switch {
+		case err != nil:
+			return event.ApplyUnknown
+		case len(diff) == 0:
+			return event.ApplyUnchanged
+		default:
+			return event.ApplyConfigured
+		}
+	}
+
+	if live.GetResourceVersion() == applied.GetResourceVersion() && live.GetGeneration() == applied.GetGeneration() {
+		return event.ApplyUnchanged
+	}
+	return event.ApplyConfigured
+}
+
+// toUnstructured return obj as an unstructured object
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if unstructuredObj, ok := obj.(*unstructured.Unstructured); ok {
+		return unstructuredObj, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// replaceObject delete the remote counterpart of obj, wait for its removal and then apply it again, returning
+// the replace events followed by the final apply event. In dry run the delete and the create are both sent to
+// the remote server without persisting them, for validating the replace.
+func (t *ApplyTask) replaceObject(ctx context.Context, obj *unstructured.Unstructured, info *resource.Info) []event.Event {
+	events := []event.Event{replaceEvent(event.StatusPending, obj, nil)}
+	if err := t.deleteAndWait(ctx, obj, info); err != nil {
+		return append(events, replaceEvent(event.StatusFailed, obj, err), applyEvent(event.StatusFailed, obj, err))
+	}
+
+	err := t.RetryPolicy.Do(ctx, "apply", func() error {
+		if t.DryRun {
+			return dryRunCreate(ctx, info, t.FieldManager)
+		}
+		return applyObject(ctx, info, false, !t.DisableForceConflicts, t.FieldManager)
+	})
+	if err != nil {
+		return append(events, replaceEvent(event.StatusFailed, obj, err), applyEvent(event.StatusFailed, obj, err))
+	}
+
+	// the object has been removed, so the apply has created it again
+	successEvent := applyEvent(event.StatusSuccessful, obj, nil)
+	successEvent.ApplyInfo.Operation = event.ApplyCreated
+	return append(events, replaceEvent(event.StatusSuccessful, obj, nil), successEvent)
+}
+
+// deleteAndWait remove the remote counterpart of obj and wait for its removal, using the DeletionPoller if
+// available or polling the object until it is not found
+func (t *ApplyTask) deleteAndWait(ctx context.Context, obj *unstructured.Unstructured, info *resource.Info) error {
+	propagationPolicy := metav1.DeletePropagationForeground
+	options := &metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	if t.DryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	err := t.RetryPolicy.Do(ctx, "delete", func() error {
+		return info.Client.Delete().
+			NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
+			Resource(info.Mapping.Resource.Resource).
+			Name(info.Name).
+			Body(options).
+			Do(ctx).
+			Error()
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if t.DryRun {
+		return nil
+	}
+
+	deletionPoller, ok := t.Poller.(poller.DeletionPoller)
+	if !ok {
+		return waitRemoved(ctx, info)
+	}
+
+	pollerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for e := range deletionPoller.StartDeletion(pollerCtx, []*unstructured.Unstructured{obj}) {
+		switch {
+		case e.Type == event.TypeError:
+			return e.ErrorInfo.Error
+		case e.Type == event.TypeStatusUpdate && e.StatusUpdateInfo.Status == event.StatusSuccessful:
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return ErrNotRemoved
+}
+
+// waitRemoved poll the remote server until the object of info cannot be found anymore, it is used for replacing
+// objects when no DeletionPoller is available
+func waitRemoved(ctx context.Context, info *resource.Info) error {
+	return wait.PollUntilContextCancel(ctx, removalPollInterval, true, func(ctx context.Context) (bool, error) {
+		err := info.Client.Get().
+			NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
+			Resource(info.Mapping.Resource.Resource).
+			Name(info.Name).
+			Do(ctx).
+			Error()
+		switch {
+		case apierrors.IsNotFound(err):
+			return true, nil
+		case err != nil:
+			return false, err
+		default:
+			return false, nil
+		}
+	})
+}
+
+// dryRunCreate validate the creation of the object of info with a server side dry run. The remote object still
+// exists because its removal was a dry run too, so an already exists error is the expected result of a create
+// that has passed the validation and the admission.
+func dryRunCreate(ctx context.Context, info *resource.Info, fieldManager string) error {
+	options := &metav1.CreateOptions{
+		FieldManager:    fieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+		DryRun:          []string{metav1.DryRunAll},
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if err != nil {
+		return err
+	}
+
+	err = info.Client.Post().
+		NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
+		Resource(info.Mapping.Resource.Resource).
+		VersionedParams(options, metav1.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Error()
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// warningEvent create a TypeWarning event for obj with message
+func warningEvent(obj *unstructured.Unstructured, message string) event.Event {
+	return event.Event{
+		Type: event.TypeWarning,
+		WarningInfo: event.WarningInfo{
+			Object:  obj,
+			Message: message,
+		},
+	}
+}
+
+// retryEvent create a TypeRetry event for a request made for obj described by info
+func retryEvent(obj *unstructured.Unstructured, info event.RetryInfo) event.Event {
+	info.Object = obj
+	return event.Event{
+		Type:      event.TypeRetry,
+		RetryInfo: info,
+	}
+}
+
+// isImmutableFieldError return true if err has been returned by the api-server for changes to immutable fields
+func isImmutableFieldError(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !apierrors.IsInvalid(err) || !errors.As(err, &statusErr) {
+		return false
+	}
+
+	messages := []string{statusErr.Status().Message}
+	if details := statusErr.Status().Details; details != nil {
+		for _, cause := range details.Causes {
+			messages = append(messages, cause.Message)
 		}
+	}
 
-		if err := applyObject(ctx, info, t.DryRun, t.FieldManager); err != nil {
-			state.SendEvent(applyEvent(event.StatusFailed, obj, err))
-			// if the error returned is unsupported media, it means that api-server don't support server side apply
-			// and so every other requests will fail as well. Bail out
-			if apierrors.IsUnsupportedMediaType(err) {
-				break
+	for _, message := range messages {
+		for _, immutableMessage := range immutableFieldMessages {
+			if strings.Contains(message, immutableMessage) {
+				return true
 			}
-			continue
 		}
+	}
+
+	return false
+}
 
-		state.SendEvent(applyEvent(event.StatusSuccessful, obj, nil))
+// replaceEvent create an Event for a replace action with the passed object and status, err will reference
+// the source of obj if it is known
+func replaceEvent(status event.Status, obj *unstructured.Unstructured, err error) event.Event {
+	return event.Event{
+		Type: event.TypeReplace,
+		ReplaceInfo: event.ReplaceInfo{
+			Status: status,
+			Object: obj,
+			Error:  pkgresource.WithSource(obj, err),
+		},
 	}
 }
 
-// applyEvent create an Event for an apply action with the passed object and status
+// applyEvent create an Event for an apply action with the passed object and status, failed events are classified
+// using err that will reference the source of obj if it is known
 func applyEvent(status event.Status, obj *unstructured.Unstructured, err error) event.Event {
-	return event.Event{
+	e := event.Event{
 		Type: event.TypeApply,
 		ApplyInfo: event.ApplyInfo{
 			Status: status,
 			Object: obj,
-			Error:  err,
+			Error:  pkgresource.WithSource(obj, err),
 		},
 	}
+
+	if status == event.StatusFailed {
+		e.ApplyInfo.Reason = event.ReasonForError(err)
+		e.ApplyInfo.Causes = event.CausesForError(err)
+	}
+	return e
 }
 
-func skippedEvent(obj *unstructured.Unstructured) event.Event {
+// skippedEvent create an Event for an apply action that has been skipped, err can contain the reason
+func skippedEvent(obj *unstructured.Unstructured, err error) event.Event {
 	return event.Event{
 		Type: event.TypeApply,
 		ApplyInfo: event.ApplyInfo{
 			Status: event.StatusSkipped,
 			Object: obj,
-			Error:  nil,
+			Error:  err,
 		},
 	}
 }
 
+// ownedObject return a copy of obj stamped with inventoryID as its owner, if its live counterpart can be adopted
+// following the policy. If inventoryID is empty obj is returned as is.
+func ownedObject(live, obj *unstructured.Unstructured, inventoryID string, policy inventory.AdoptionPolicy) (*unstructured.Unstructured, error) {
+	if inventoryID == "" {
+		return obj, nil
+	}
+
+	if err := inventory.CanAdopt(live, inventoryID, policy); err != nil {
+		return nil, err
+	}
+
+	ownedObj := obj.DeepCopy()
+	inventory.SetOwner(ownedObj, inventoryID)
+	return ownedObj, nil
+}
+
 // applyObject encapsulate the logic for making a PATCH request to the api-server with server side merging logic
 // and strict validation of the resource fields
-func applyObject(ctx context.Context, info *resource.Info, dryRun bool, fieldManager string) error {
-	forceConflictingFields := true
+func applyObject(ctx context.Context, info *resource.Info, dryRun, forceConflictingFields bool, fieldManager string) error {
 	options := &metav1.PatchOptions{
 		Force:           &forceConflictingFields,
 		FieldManager:    fieldManager,
@@ -162,15 +622,7 @@ func applyObject(ctx context.Context, info *resource.Info, dryRun bool, fieldMan
 		return err
 	}
 
-	obj, err := info.Client.Patch(types.ApplyPatchType).
-		NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
-		Resource(info.Mapping.Resource.Resource).
-		Name(info.Name).
-		VersionedParams(options, metav1.ParameterCodec).
-		Body(data).
-		Do(ctx).
-		Get()
-
+	obj, err := serverSideApply(ctx, info, options, data)
 	if err != nil {
 		if apierrors.IsUnsupportedMediaType(err) {
 			err = fmt.Errorf("server-side apply not available on the server: %w", err)
@@ -182,26 +634,66 @@ func applyObject(ctx context.Context, info *resource.Info, dryRun bool, fieldMan
 	_ = info.Refresh(obj, true)
 
 	if migrated, err := migrateToSSAIfNecessary(ctx, info, fieldManager); err != nil {
-		fmt.Fprintf(os.Stderr, warningMigrationPatchFailed, err.Error())
+		util.Warn(ctx, fmt.Sprintf(warningMigrationPatchFailed, err.Error()))
 	} else if migrated {
-		if _, err := info.Client.Patch(types.ApplyPatchType).
-			NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
-			Resource(info.Mapping.Resource.Resource).
-			Name(info.Name).
-			VersionedParams(options, metav1.ParameterCodec).
-			Body(data).
-			Do(ctx).
-			Get(); err != nil {
-			fmt.Fprintf(os.Stderr, warningMigrationReapplyFailed, err.Error())
+		if _, err := serverSideApply(ctx, info, options, data); err != nil {
+			util.Warn(ctx, fmt.Sprintf(warningMigrationReapplyFailed, err.Error()))
 		}
 	} else {
 		_ = info.Refresh(obj, true)
 	}
 
-	warnIfDeleting(info.Object)
+	warnIfDeleting(ctx, info.Object)
 	return nil
 }
 
+// conflictsFromError return the fields conflicts reported by the api-server in err, if any
+func conflictsFromError(err error) []event.Conflict {
+	var statusErr apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return nil
+	}
+
+	var conflicts []event.Conflict
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		conflicts = append(conflicts, event.Conflict{
+			Field:   cause.Field,
+			Manager: managerFromConflictMessage(cause.Message),
+			Message: cause.Message,
+		})
+	}
+
+	return conflicts
+}
+
+// managerFromConflictMessage extract the field manager name from a conflict message returned by the api-server
+// in the form of: conflict with "manager" using apps/v1
+func managerFromConflictMessage(message string) string {
+	quotedManager, err := strconv.QuotedPrefix(strings.TrimPrefix(message, "conflict with "))
+	if err != nil {
+		return ""
+	}
+
+	manager, _ := strconv.Unquote(quotedManager)
+	return manager
+}
+
+// serverSideApply send data to the api-server as an apply patch for the info resource
+func serverSideApply(ctx context.Context, info *resource.Info, options *metav1.PatchOptions, data []byte) (runtime.Object, error) {
+	return info.Client.Patch(types.ApplyPatchType).
+		NamespaceIfScoped(info.Namespace, info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace).
+		Resource(info.Mapping.Resource.Resource).
+		Name(info.Name).
+		VersionedParams(options, metav1.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Get()
+}
+
 func DefaultInfoFetcherBuilder(factory util.ClientFactory) (InfoFetcher, error) {
 	mapper, err := factory.ToRESTMapper()
 	if err != nil {
@@ -297,9 +789,9 @@ func migrateToSSAIfNecessary(ctx context.Context, info *resource.Info, fieldMana
 	return false, err
 }
 
-// warnIfDeleting prints a warning if a resource is being deleted
-func warnIfDeleting(obj runtime.Object) {
+// warnIfDeleting send a warning to the WarningFunc of ctx if a resource is being deleted
+func warnIfDeleting(ctx context.Context, obj runtime.Object) {
 	if metadata, _ := meta.Accessor(obj); metadata != nil && metadata.GetDeletionTimestamp() != nil {
-		fmt.Fprintf(os.Stderr, warningChangesOnDeletingResource, metadata.GetName())
+		util.Warn(ctx, fmt.Sprintf(warningChangesOnDeletingResource, metadata.GetName()))
 	}
 }
@@ -20,26 +20,39 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
 	"k8s.io/client-go/util/csaupgrade"
 
 	"github.com/mia-platform/jpl/pkg/client/cache"
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/filter"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/poller"
+	pkgresource "github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
 	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
 )
@@ -455,6 +468,884 @@ func TestApplyTask(t *testing.T) {
 	}
 }
 
+func TestConcurrentApplyTask(t *testing.T) {
+	t.Parallel()
+
+	concurrency := 4
+	objects := make([]*unstructured.Unstructured, 0, 20)
+	expectedEvents := make([]event.Event, 0, 40)
+	for idx := range 20 {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(fmt.Sprintf("config-%d", idx))
+		configMap.SetNamespace("test")
+		objects = append(objects, configMap)
+		expectedEvents = append(expectedEvents,
+			event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: configMap}},
+			event.Event{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusSuccessful, Object: configMap}},
+		)
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	handler := func(r *http.Request) (*http.Response, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := maxInFlight.Load()
+			if current <= old || maxInFlight.CompareAndSwap(old, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+	}
+
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	defaultInfoFetcher, err := DefaultInfoFetcherBuilder(tf)
+	require.NoError(t, err)
+
+	// the fake RESTClient is not safe for concurrent use, so every object will receive its own
+	infoFetcher := func(obj *unstructured.Unstructured) (*resource.Info, error) {
+		info, err := defaultInfoFetcher(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		info.Client = &fake.RESTClient{
+			NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			Client:               fake.CreateHTTPClient(handler),
+		}
+		return info, nil
+	}
+
+	task := &ApplyTask{
+		FieldManager: "test",
+		Concurrency:  concurrency,
+		InfoFetcher:  infoFetcher,
+		Objects:      objects,
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, len(expectedEvents))
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+	}
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(concurrency))
+}
+
+func TestConcurrentApplyTaskRemoteGets(t *testing.T) {
+	t.Parallel()
+
+	objects := make([]*unstructured.Unstructured, 0, 2)
+	for idx := range 2 {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(fmt.Sprintf("config-%d", idx))
+		configMap.SetNamespace("test")
+		objects = append(objects, configMap)
+	}
+
+	// every get is blocked until both of them are in flight, if the remote getter serialize the requests the
+	// first one will return only after the timeout
+	var inFlight atomic.Int32
+	var timedOut atomic.Bool
+	bothInFlight := make(chan struct{})
+	getHandler := func(r *http.Request) (*http.Response, error) {
+		if inFlight.Add(1) == int32(len(objects)) {
+			close(bothInFlight)
+		}
+
+		select {
+		case <-bothInFlight:
+		case <-time.After(2 * time.Second):
+			timedOut.Store(true)
+		}
+
+		status := apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, path.Base(r.URL.Path)).ErrStatus
+		status.APIVersion = "v1"
+		status.Kind = "Status"
+		data, err := json.Marshal(status)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusNotFound, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+	}
+
+	dynamicClient, err := dynamic.NewForConfigAndClient(&rest.Config{Host: "http://localhost"}, fake.CreateHTTPClient(getHandler))
+	require.NoError(t, err)
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	applyHandler := func(r *http.Request) (*http.Response, error) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+	}
+
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	defaultInfoFetcher, err := DefaultInfoFetcherBuilder(tf)
+	require.NoError(t, err)
+
+	infoFetcher := func(obj *unstructured.Unstructured) (*resource.Info, error) {
+		info, err := defaultInfoFetcher(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		info.Client = &fake.RESTClient{
+			NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			Client:               fake.CreateHTTPClient(applyHandler),
+		}
+		return info, nil
+	}
+
+	task := &ApplyTask{
+		FieldManager: "test",
+		Concurrency:  len(objects),
+		InfoFetcher:  infoFetcher,
+		RemoteGetter: cache.NewCachedResourceGetter(mapper, dynamicClient),
+		Objects:      objects,
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, 2*len(objects))
+	for _, sentEvent := range state.SentEvents {
+		assert.NoError(t, sentEvent.ApplyInfo.Error)
+	}
+	assert.False(t, timedOut.Load(), "remote gets are not made concurrently")
+}
+
+func TestApplyTaskPreviousFailures(t *testing.T) {
+	t.Parallel()
+
+	namespace := pkgtesting.UnstructuredFromFile(t, namespaceFilename)
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	deployment.SetNamespace(namespace.GetName())
+	graph, err := pkgresource.NewDependencyGraph([]*unstructured.Unstructured{namespace, deployment})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		failedObjects  sets.Set[pkgresource.ObjectMetadata]
+		failFast       bool
+		expectedEvents []event.Event
+	}{
+		"skip object with failed dependency": {
+			failedObjects: sets.New(pkgresource.ObjectMetadataFromUnstructured(namespace)),
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Status: event.StatusSkipped,
+						Object: deployment,
+						Error:  pkgresource.DependencyFailedError{Dependency: pkgresource.ObjectMetadataFromUnstructured(namespace)},
+					},
+				},
+			},
+		},
+		"skip object after any failure with fail fast": {
+			failedObjects: sets.New(pkgresource.ObjectMetadata{Kind: "ConfigMap", Name: "other", Namespace: "test"}),
+			failFast:      true,
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Status: event.StatusSkipped,
+						Object: deployment,
+						Error:  ErrFailFast,
+					},
+				},
+			},
+		},
+		"apply object if the failure is not a dependency": {
+			failedObjects: sets.New(pkgresource.ObjectMetadata{Kind: "ConfigMap", Name: "other", Namespace: "test"}),
+			expectedEvents: []event.Event{
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: deployment}},
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusSuccessful, Object: deployment}},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					data, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+				}),
+			}
+			infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+			require.NoError(t, err)
+
+			task := &ApplyTask{
+				FieldManager: "test",
+				FailFast:     testCase.failFast,
+				Graph:        graph,
+				InfoFetcher:  infoFetcher,
+				Objects:      []*unstructured.Unstructured{deployment},
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout, FailedObjects: testCase.failedObjects}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, len(testCase.expectedEvents))
+			for idx, expectedEvent := range testCase.expectedEvents {
+				assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+			}
+		})
+	}
+}
+
+func TestApplyTaskConflicts(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	pkgresource.SetSource(deployment, pkgresource.Source{Path: "manifests/deployment.yaml", Line: 1})
+	conflictErr := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kube-controller-manager" using apps/v1`,
+			Field:   ".spec.replicas",
+		},
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "operator"`,
+			Field:   ".metadata.labels.app",
+		},
+	}, "Apply failed with 2 conflicts")
+	conflictErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+			switch path, method := r.URL.Path, r.Method; {
+			case method == http.MethodPatch && path == deployPath:
+				assert.Equal(t, "false", r.URL.Query().Get("force"))
+				data, err := json.Marshal(conflictErr.ErrStatus)
+				require.NoError(t, err)
+				return &http.Response{StatusCode: http.StatusConflict, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+			default:
+				t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+				return nil, errors.New("unexpected request")
+			}
+		}),
+	}
+	infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+	require.NoError(t, err)
+
+	task := &ApplyTask{
+		FieldManager:          "test",
+		DisableForceConflicts: true,
+		InfoFetcher:           infoFetcher,
+		Objects:               []*unstructured.Unstructured{deployment},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, 2)
+	failedEvent := state.SentEvents[1]
+	assert.Equal(t, event.StatusFailed, failedEvent.ApplyInfo.Status)
+	assert.True(t, apierrors.IsConflict(failedEvent.ApplyInfo.Error))
+	assert.ErrorContains(t, failedEvent.ApplyInfo.Error, "manifests/deployment.yaml:1: Apply failed with 2 conflicts")
+	assert.Equal(t, event.ReasonConflict, failedEvent.ApplyInfo.Reason)
+	assert.Len(t, failedEvent.ApplyInfo.Causes, 2)
+	assert.Equal(t, []event.Conflict{
+		{
+			Field:   ".spec.replicas",
+			Manager: "kube-controller-manager",
+			Message: `conflict with "kube-controller-manager" using apps/v1`,
+		},
+		{
+			Field:   ".metadata.labels.app",
+			Manager: "operator",
+			Message: `conflict with "operator"`,
+		},
+	}, failedEvent.ApplyInfo.Conflicts)
+}
+
+func TestApplyTaskDeletingWarning(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+			switch path, method := r.URL.Path, r.Method; {
+			case method == http.MethodPatch && path == deployPath:
+				response := pkgtesting.UnstructuredFromFile(t, deploymentAppliedFilename)
+				response.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+				data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), response)
+				assert.NoError(t, err)
+				return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+			default:
+				t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+				return nil, errors.New("unexpected request")
+			}
+		}),
+	}
+	infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+	require.NoError(t, err)
+
+	task := &ApplyTask{
+		FieldManager: "test",
+		Poller:       &poller.FakePoller{},
+		InfoFetcher:  infoFetcher,
+		Objects:      []*unstructured.Unstructured{deployment},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	expectedEvents := []event.Event{
+		applyEvent(event.StatusPending, deployment, nil),
+		warningEvent(deployment, "try to change nginx resource which is currently being deleted"),
+		applyEvent(event.StatusSuccessful, deployment, nil),
+	}
+	require.Len(t, state.SentEvents, len(expectedEvents))
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+	}
+}
+
+func TestApplyTaskRetry(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	throttledErr := apierrors.NewTooManyRequests("too many requests", 0)
+	throttledErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+
+	var patches atomic.Int32
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+			switch path, method := r.URL.Path, r.Method; {
+			case method == http.MethodPatch && path == deployPath:
+				if patches.Add(1) == 1 {
+					data, err := json.Marshal(throttledErr.ErrStatus)
+					assert.NoError(t, err)
+					return &http.Response{StatusCode: http.StatusTooManyRequests, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+				}
+				response := pkgtesting.UnstructuredFromFile(t, deploymentAppliedFilename)
+				data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), response)
+				assert.NoError(t, err)
+				return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+			default:
+				t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+				return nil, errors.New("unexpected request")
+			}
+		}),
+	}
+	infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+	require.NoError(t, err)
+
+	task := &ApplyTask{
+		FieldManager: "test",
+		RetryPolicy:  &retry.Policy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+		InfoFetcher:  infoFetcher,
+		Objects:      []*unstructured.Unstructured{deployment},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, 3)
+	assert.Equal(t, applyEvent(event.StatusPending, deployment, nil).String(), state.SentEvents[0].String())
+	assert.Equal(t, event.TypeRetry, state.SentEvents[1].Type)
+	assert.Equal(t, deployment, state.SentEvents[1].RetryInfo.Object)
+	assert.Equal(t, "apply", state.SentEvents[1].RetryInfo.Operation)
+	assert.Equal(t, 1, state.SentEvents[1].RetryInfo.Attempt)
+	assert.True(t, apierrors.IsTooManyRequests(state.SentEvents[1].RetryInfo.Error))
+	assert.Equal(t, applyEvent(event.StatusSuccessful, deployment, nil).String(), state.SentEvents[2].String())
+	assert.Equal(t, int32(2), patches.Load())
+}
+
+func TestApplyTaskReplace(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	annotatedDeployment := deployment.DeepCopy()
+	annotatedDeployment.SetAnnotations(map[string]string{pkgresource.ReplaceAnnotation: pkgresource.ReplaceOnImmutable})
+
+	immutableErr := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "nginx", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable"),
+	})
+	immutableErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	deleteErr := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx", errors.New("not allowed"))
+	createdEvent := func(obj *unstructured.Unstructured) event.Event {
+		e := applyEvent(event.StatusSuccessful, obj, nil)
+		e.ApplyInfo.Operation = event.ApplyCreated
+		return e
+	}
+	deleteErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	existsErr := apierrors.NewAlreadyExists(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx")
+	existsErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	admissionErr := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx", errors.New("denied by admission webhook"))
+	admissionErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	notFoundErr := apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx")
+	notFoundErr.ErrStatus.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+
+	testCases := map[string]struct {
+		object             *unstructured.Unstructured
+		replaceOnImmutable bool
+		dryRun             bool
+		poller             poller.StatusPoller
+		deleteError        *apierrors.StatusError
+		createError        *apierrors.StatusError
+		expectedEvents     []event.Event
+		expectedDeletes    int32
+		expectedPatches    int32
+		expectedCreates    int32
+		expectedGets       int32
+	}{
+		"replace with global option": {
+			object:             deployment,
+			replaceOnImmutable: true,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusSuccessful, deployment, nil),
+				createdEvent(deployment),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 2,
+		},
+		"replace with annotation": {
+			object: annotatedDeployment,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, annotatedDeployment, nil),
+				replaceEvent(event.StatusPending, annotatedDeployment, nil),
+				replaceEvent(event.StatusSuccessful, annotatedDeployment, nil),
+				createdEvent(annotatedDeployment),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 2,
+		},
+		"replace in dry run": {
+			object:             deployment,
+			replaceOnImmutable: true,
+			dryRun:             true,
+			createError:        existsErr,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusSuccessful, deployment, nil),
+				createdEvent(deployment),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 1,
+			expectedCreates: 1,
+		},
+		"replace in dry run rejected by the server": {
+			object:             deployment,
+			replaceOnImmutable: true,
+			dryRun:             true,
+			createError:        admissionErr,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusFailed, deployment, admissionErr),
+				applyEvent(event.StatusFailed, deployment, admissionErr),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 1,
+			expectedCreates: 1,
+		},
+		"replace without deletion poller wait for the object to be not found": {
+			object:             deployment,
+			replaceOnImmutable: true,
+			poller:             &statusOnlyPoller{StatusPoller: &poller.FakePoller{}},
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusSuccessful, deployment, nil),
+				createdEvent(deployment),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 2,
+			expectedGets:    2,
+		},
+		"immutable error without replace": {
+			object: deployment,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				applyEvent(event.StatusFailed, deployment, immutableErr),
+			},
+			expectedPatches: 1,
+		},
+		"delete fails": {
+			object:             deployment,
+			replaceOnImmutable: true,
+			deleteError:        deleteErr,
+			expectedEvents: []event.Event{
+				applyEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusPending, deployment, nil),
+				replaceEvent(event.StatusFailed, deployment, deleteErr),
+				applyEvent(event.StatusFailed, deployment, deleteErr),
+			},
+			expectedDeletes: 1,
+			expectedPatches: 1,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var patches, deletes, creates, gets atomic.Int32
+			tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					if testCase.dryRun && r.Method == http.MethodPatch {
+						assert.Equal(t, "All", r.URL.Query().Get("dryRun"))
+					}
+					switch path, method := r.URL.Path, r.Method; {
+					case method == http.MethodPatch && path == deployPath && patches.Add(1) == 1:
+						data, err := json.Marshal(immutableErr.ErrStatus)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: http.StatusUnprocessableEntity, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					case method == http.MethodPatch && path == deployPath:
+						response := pkgtesting.UnstructuredFromFile(t, deploymentAppliedFilename)
+						data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), response)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					case method == http.MethodDelete && path == deployPath:
+						deletes.Add(1)
+						if testCase.dryRun {
+							data, err := io.ReadAll(r.Body)
+							require.NoError(t, err)
+							assert.Contains(t, string(data), `"dryRun":["All"]`)
+						}
+						if testCase.deleteError != nil {
+							data, err := json.Marshal(testCase.deleteError.ErrStatus)
+							require.NoError(t, err)
+							return &http.Response{StatusCode: http.StatusForbidden, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader([]byte(`{}`)))}, nil
+					case method == http.MethodPost && path == "/namespaces/test/deployments":
+						creates.Add(1)
+						assert.Equal(t, "All", r.URL.Query().Get("dryRun"))
+						data, err := json.Marshal(testCase.createError.ErrStatus)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: int(testCase.createError.ErrStatus.Code), Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					case method == http.MethodGet && path == deployPath && gets.Add(1) == 1:
+						response := pkgtesting.UnstructuredFromFile(t, deploymentAppliedFilename)
+						data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), response)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					case method == http.MethodGet && path == deployPath:
+						data, err := json.Marshal(notFoundErr.ErrStatus)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: http.StatusNotFound, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("unexpected request")
+					}
+				}),
+			}
+			infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+			require.NoError(t, err)
+
+			statusPoller := testCase.poller
+			if statusPoller == nil {
+				statusPoller = &poller.FakePoller{}
+			}
+
+			task := &ApplyTask{
+				FieldManager:       "test",
+				DryRun:             testCase.dryRun,
+				ReplaceOnImmutable: testCase.replaceOnImmutable,
+				Poller:             statusPoller,
+				InfoFetcher:        infoFetcher,
+				Objects:            []*unstructured.Unstructured{testCase.object},
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, len(testCase.expectedEvents))
+			for idx, expectedEvent := range testCase.expectedEvents {
+				assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+			}
+			assert.Equal(t, testCase.expectedDeletes, deletes.Load())
+			assert.Equal(t, testCase.expectedPatches, patches.Load())
+			assert.Equal(t, testCase.expectedCreates, creates.Load())
+			assert.Equal(t, testCase.expectedGets, gets.Load())
+		})
+	}
+}
+
+// statusOnlyPoller is a StatusPoller that does not implement the poller.DeletionPoller interface
+type statusOnlyPoller struct {
+	poller.StatusPoller
+}
+
+func TestApplyTaskOwnership(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+	inventoryID := "test/inventory"
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	ownedDeployment := func(owner string) *unstructured.Unstructured {
+		obj := deployment.DeepCopy()
+		inventory.SetOwner(obj, owner)
+		return obj
+	}
+
+	testCases := map[string]struct {
+		remoteObjects  []*unstructured.Unstructured
+		policy         inventory.AdoptionPolicy
+		expectedEvents []event.Event
+	}{
+		"new object is stamped with the inventory": {
+			expectedEvents: []event.Event{
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: deployment}},
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusSuccessful, Object: deployment, Operation: event.ApplyCreated}},
+			},
+		},
+		"object owned by the same inventory": {
+			remoteObjects: []*unstructured.Unstructured{ownedDeployment(inventoryID)},
+			policy:        inventory.AdoptNever,
+			expectedEvents: []event.Event{
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: deployment}},
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusSuccessful, Object: deployment, Operation: event.ApplyUnchanged}},
+			},
+		},
+		"object owned by another inventory": {
+			remoteObjects: []*unstructured.Unstructured{ownedDeployment("test/other")},
+			policy:        inventory.AdoptIfUnowned,
+			expectedEvents: []event.Event{
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: deployment}},
+				{
+					Type: event.TypeApply,
+					ApplyInfo: event.ApplyInfo{
+						Status: event.StatusFailed,
+						Object: deployment,
+						Error:  &inventory.OwnershipError{Owner: "test/other", Inventory: inventoryID},
+					},
+				},
+			},
+		},
+		"object owned by another inventory is adopted": {
+			remoteObjects: []*unstructured.Unstructured{ownedDeployment("test/other")},
+			policy:        inventory.AdoptAlways,
+			expectedEvents: []event.Event{
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusPending, Object: deployment}},
+				{Type: event.TypeApply, ApplyInfo: event.ApplyInfo{Status: event.StatusSuccessful, Object: deployment, Operation: event.ApplyUnchanged}},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					switch path, method := r.URL.Path, r.Method; {
+					case method == http.MethodPatch && path == deployPath:
+						data, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						applied, _, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+						require.NoError(t, err)
+						assert.Equal(t, inventoryID, inventory.OwnerOf(applied.(*unstructured.Unstructured)))
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("unexpected request")
+					}
+				}),
+			}
+			infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+			require.NoError(t, err)
+
+			task := &ApplyTask{
+				FieldManager:   "test",
+				InventoryID:    inventoryID,
+				AdoptionPolicy: testCase.policy,
+				InfoFetcher:    infoFetcher,
+				RemoteGetter:   &testRemoteGetter{objects: testCase.remoteObjects},
+				Objects:        []*unstructured.Unstructured{deployment},
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, len(testCase.expectedEvents))
+			for idx, expectedEvent := range testCase.expectedEvents {
+				assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+			}
+			assert.Empty(t, inventory.OwnerOf(deployment), "the original object must not be modified")
+		})
+	}
+}
+
+func TestApplyTaskOperation(t *testing.T) {
+	t.Parallel()
+
+	configMapPath := "/namespaces/test/configmaps/config"
+	configMap := func(resourceVersion string, data map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      "config",
+				"namespace": "test",
+			},
+		}}
+		if resourceVersion != "" {
+			obj.SetResourceVersion(resourceVersion)
+		}
+		require.NoError(t, unstructured.SetNestedStringMap(obj.Object, data, "data"))
+		return obj
+	}
+	localObject := configMap("", map[string]string{"key": "value"})
+
+	testCases := map[string]struct {
+		live              *unstructured.Unstructured
+		applied           *unstructured.Unstructured
+		dryRun            bool
+		noRemoteGetter    bool
+		poller            poller.StatusPoller
+		expectedOperation event.ApplyOperation
+		expectedCurrent   bool
+	}{
+		"missing object is created": {
+			applied:           configMap("1", map[string]string{"key": "value"}),
+			poller:            &poller.FakePoller{},
+			expectedOperation: event.ApplyCreated,
+		},
+		"changed object is configured": {
+			live:              configMap("1", map[string]string{"key": "old"}),
+			applied:           configMap("2", map[string]string{"key": "value"}),
+			poller:            &poller.FakePoller{},
+			expectedOperation: event.ApplyConfigured,
+		},
+		"unchanged object is current": {
+			live:              configMap("1", map[string]string{"key": "value"}),
+			applied:           configMap("1", map[string]string{"key": "value"}),
+			poller:            &poller.FakePoller{},
+			expectedOperation: event.ApplyUnchanged,
+			expectedCurrent:   true,
+		},
+		"unchanged object without status checker": {
+			live:              configMap("1", map[string]string{"key": "value"}),
+			applied:           configMap("1", map[string]string{"key": "value"}),
+			expectedOperation: event.ApplyUnchanged,
+		},
+		"changed object in dry run": {
+			live:              configMap("1", map[string]string{"key": "old"}),
+			applied:           configMap("1", map[string]string{"key": "value"}),
+			dryRun:            true,
+			poller:            &poller.FakePoller{},
+			expectedOperation: event.ApplyConfigured,
+		},
+		"unchanged object in dry run": {
+			live:              configMap("1", map[string]string{"key": "value"}),
+			applied:           configMap("1", map[string]string{"key": "value"}),
+			dryRun:            true,
+			poller:            &poller.FakePoller{},
+			expectedOperation: event.ApplyUnchanged,
+			expectedCurrent:   true,
+		},
+		"unknown live object": {
+			applied:           configMap("2", map[string]string{"key": "value"}),
+			noRemoteGetter:    true,
+			expectedOperation: event.ApplyUnknown,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					switch path, method := r.URL.Path, r.Method; {
+					case method == http.MethodPatch && path == configMapPath:
+						data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), testCase.applied)
+						require.NoError(t, err)
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("unexpected request")
+					}
+				}),
+			}
+			infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+			require.NoError(t, err)
+
+			task := &ApplyTask{
+				FieldManager: "test",
+				DryRun:       testCase.dryRun,
+				Poller:       testCase.poller,
+				InfoFetcher:  infoFetcher,
+				Objects:      []*unstructured.Unstructured{localObject},
+			}
+			if !testCase.noRemoteGetter {
+				var remoteObjects []*unstructured.Unstructured
+				if testCase.live != nil {
+					remoteObjects = append(remoteObjects, testCase.live)
+				}
+				task.RemoteGetter = &testRemoteGetter{objects: remoteObjects}
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, 2)
+			info := state.SentEvents[1].ApplyInfo
+			require.Equal(t, event.StatusSuccessful, info.Status)
+			assert.Equal(t, testCase.expectedOperation, info.Operation)
+			assert.Equal(t, testCase.expectedCurrent, info.Current)
+		})
+	}
+}
+
 func TestClientSideMigration(t *testing.T) {
 	t.Parallel()
 
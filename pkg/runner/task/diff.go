@@ -0,0 +1,248 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mia-platform/jpl/pkg/client/cache"
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/filter"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	pkgresource "github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/runner"
+)
+
+const (
+	diffContextLines = 3
+	diffLiveFile     = "live"
+	diffMergedFile   = "merged"
+)
+
+// diffIgnoredFields are the paths populated by the api-server that are removed before computing a diff
+var diffIgnoredFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "selfLink"},
+	{"status"},
+}
+
+// keep it to always check if DiffTask implement correctly the Task interface
+var _ runner.Task = &DiffTask{}
+var _ runner.Describer = &DiffTask{}
+
+// DiffTask will compute the changes that applying the Objects will make on the remote api-server, using a
+// server-side dry run apply, without modifying them. If Prune is true the Objects are the ones that will be deleted.
+type DiffTask struct {
+	FieldManager string
+	Prune        bool
+
+	// InventoryID, AdoptionPolicy and DisableForceConflicts have the same meaning of the ones of ApplyTask
+	// and PruneTask
+	InventoryID           string
+	AdoptionPolicy        inventory.AdoptionPolicy
+	DisableForceConflicts bool
+
+	RemoteGetter cache.RemoteResourceGetter
+	Objects      []*unstructured.Unstructured
+	Filters      []filter.Interface
+	InfoFetcher  InfoFetcher
+}
+
+// Kind implement the runner.Describer interface
+func (t *DiffTask) Kind() string {
+	return "diff"
+}
+
+// ObjectsCount implement the runner.Describer interface
+func (t *DiffTask) ObjectsCount() int {
+	return len(t.Objects)
+}
+
+// Run implement the runner.Task interface
+func (t *DiffTask) Run(state runner.State) {
+	ctx := state.GetContext()
+
+	for _, obj := range t.Objects {
+		if t.Prune {
+			// objects annotated for preventing their deletion will be left untouched on the remote server
+			if pkgresource.IsDeletionPrevented(obj) {
+				state.SendEvent(diffEvent(event.DiffSkipped, obj, "", nil))
+				continue
+			}
+
+			if err := canPrune(obj, t.InventoryID); err != nil {
+				state.SendEvent(diffEvent(event.DiffSkipped, obj, "", err))
+				continue
+			}
+
+			diff, err := objectsDiff(obj, nil)
+			if err != nil {
+				state.SendEvent(diffEvent(event.DiffFailed, obj, "", err))
+				continue
+			}
+			state.SendEvent(diffEvent(event.DiffPrune, obj, diff, nil))
+			continue
+		}
+
+		filteredObj := false
+		for _, filter := range t.Filters {
+			filtered, filterError := filter.Filter(obj, t.RemoteGetter)
+			if filterError != nil {
+				state.SendEvent(diffEvent(event.DiffFailed, obj, "", filterError))
+				filteredObj = true
+				break
+			}
+
+			if filtered {
+				state.SendEvent(diffEvent(event.DiffSkipped, obj, "", nil))
+				filteredObj = true
+				break
+			}
+		}
+
+		if filteredObj {
+			continue
+		}
+
+		state.SendEvent(t.diffObject(ctx, obj))
+	}
+}
+
+// diffObject return the diff event between the remote status of obj and the result of its dry run apply
+func (t *DiffTask) diffObject(ctx context.Context, obj *unstructured.Unstructured) event.Event {
+	live, err := t.RemoteGetter.Get(ctx, pkgresource.ObjectMetadataFromUnstructured(obj))
+	if err != nil {
+		return diffEvent(event.DiffFailed, obj, "", err)
+	}
+
+	ownedObj, err := ownedObject(live, obj, t.InventoryID, t.AdoptionPolicy)
+	if err != nil {
+		return diffEvent(event.DiffFailed, obj, "", err)
+	}
+
+	merged, err := t.dryRunApply(ctx, ownedObj)
+	if err != nil {
+		// the remote server cannot validate objects that depend on others not yet created, like resources inside
+		// a new namespace or custom resources of a new CRD, in these cases the local object is the best guess
+		if live != nil || !(apierrors.IsNotFound(err) || meta.IsNoMatchError(err)) {
+			return diffEvent(event.DiffFailed, obj, "", err)
+		}
+		merged = ownedObj
+	}
+
+	diff, err := objectsDiff(live, merged)
+	switch {
+	case err != nil:
+		return diffEvent(event.DiffFailed, obj, "", err)
+	case live == nil:
+		return diffEvent(event.DiffCreate, obj, diff, nil)
+	case len(diff) == 0:
+		return diffEvent(event.DiffUnchanged, obj, diff, nil)
+	default:
+		return diffEvent(event.DiffUpdate, obj, diff, nil)
+	}
+}
+
+// dryRunApply return the object as it will be after applying obj, without persisting the changes
+func (t *DiffTask) dryRunApply(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	info, err := t.InfoFetcher(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	forceConflictingFields := !t.DisableForceConflicts
+	options := &metav1.PatchOptions{
+		Force:           &forceConflictingFields,
+		FieldManager:    t.FieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+		DryRun:          []string{metav1.DryRunAll},
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := serverSideApply(ctx, info, options, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUnstructured(result)
+}
+
+// diffEvent create an Event for a diff action with the passed object and action, err will reference the source
+// of obj if it is known
+func diffEvent(action event.DiffAction, obj *unstructured.Unstructured, diff string, err error) event.Event {
+	return event.Event{
+		Type: event.TypeDiff,
+		DiffInfo: event.DiffInfo{
+			Object: obj,
+			Action: action,
+			Diff:   diff,
+			Error:  pkgresource.WithSource(obj, err),
+		},
+	}
+}
+
+// objectsDiff return the unified diff between the yaml representation of live and merged, a nil object is
+// considered empty
+func objectsDiff(live, merged *unstructured.Unstructured) (string, error) {
+	liveData, err := diffableYAML(live)
+	if err != nil {
+		return "", err
+	}
+
+	mergedData, err := diffableYAML(merged)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveData),
+		B:        difflib.SplitLines(mergedData),
+		FromFile: diffLiveFile,
+		ToFile:   diffMergedFile,
+		Context:  diffContextLines,
+	})
+}
+
+// diffableYAML return the yaml representation of obj without the fields populated by the api-server
+func diffableYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+
+	obj = obj.DeepCopy()
+	for _, fields := range diffIgnoredFields {
+		unstructured.RemoveNestedField(obj.Object, fields...)
+	}
+
+	data, err := yaml.Marshal(obj.Object)
+	return string(data), err
+}
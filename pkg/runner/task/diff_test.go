@@ -0,0 +1,200 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+
+	"github.com/mia-platform/jpl/pkg/client/cache"
+	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/filter"
+	pkgresource "github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/runner"
+	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
+)
+
+func TestDiffTask(t *testing.T) {
+	t.Parallel()
+
+	deployPath := "/namespaces/test/deployments/nginx"
+
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	deploymentApplied := pkgtesting.UnstructuredFromFile(t, deploymentAppliedFilename)
+	oldDeployment := deploymentApplied.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(oldDeployment.Object, int64(3), "spec", "replicas"))
+	preventedDeployment := deploymentApplied.DeepCopy()
+	preventedDeployment.SetAnnotations(map[string]string{pkgresource.LifecycleDeletionAnnotation: pkgresource.PreventDeletion})
+
+	testCases := map[string]struct {
+		resources      []*unstructured.Unstructured
+		remoteObjects  []*unstructured.Unstructured
+		statusCode     int
+		filters        []filter.Interface
+		prune          bool
+		expectedAction event.DiffAction
+		expectedDiff   []string
+		expectedError  string
+	}{
+		"new object will be created": {
+			resources:      []*unstructured.Unstructured{deployment},
+			statusCode:     http.StatusOK,
+			expectedAction: event.DiffCreate,
+			expectedDiff:   []string{"--- live\n", "+++ merged\n", "+kind: Deployment\n", "+  replicas: 1\n"},
+		},
+		"existing object will be updated": {
+			resources:      []*unstructured.Unstructured{deployment},
+			remoteObjects:  []*unstructured.Unstructured{oldDeployment},
+			statusCode:     http.StatusOK,
+			expectedAction: event.DiffUpdate,
+			expectedDiff:   []string{"-  replicas: 3\n", "+  replicas: 1\n"},
+		},
+		"existing object is unchanged": {
+			resources:      []*unstructured.Unstructured{deployment},
+			remoteObjects:  []*unstructured.Unstructured{deploymentApplied},
+			statusCode:     http.StatusOK,
+			expectedAction: event.DiffUnchanged,
+		},
+		"new object in a missing namespace use the local object": {
+			resources:      []*unstructured.Unstructured{deployment},
+			statusCode:     http.StatusNotFound,
+			expectedAction: event.DiffCreate,
+			expectedDiff:   []string{"+kind: Deployment\n", "+      app: nginx\n"},
+		},
+		"error during dry run": {
+			resources:      []*unstructured.Unstructured{deployment},
+			remoteObjects:  []*unstructured.Unstructured{oldDeployment},
+			statusCode:     http.StatusForbidden,
+			expectedAction: event.DiffFailed,
+			expectedError:  "patch deployments nginx",
+		},
+		"error during filtering": {
+			resources:      []*unstructured.Unstructured{deployment},
+			filters:        []filter.Interface{&testFilter{Error: errors.New("error in filter")}},
+			expectedAction: event.DiffFailed,
+			expectedError:  "error in filter",
+		},
+		"filtered object will be skipped": {
+			resources:      []*unstructured.Unstructured{deployment},
+			filters:        []filter.Interface{&testFilter{Kind: "Deployment"}},
+			expectedAction: event.DiffSkipped,
+		},
+		"prune object": {
+			resources:      []*unstructured.Unstructured{deploymentApplied},
+			prune:          true,
+			expectedAction: event.DiffPrune,
+			expectedDiff:   []string{"-kind: Deployment\n", "-  replicas: 1\n"},
+		},
+		"prune object with deletion prevented will be skipped": {
+			resources:      []*unstructured.Unstructured{preventedDeployment},
+			prune:          true,
+			expectedAction: event.DiffSkipped,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+					require.Equal(t, string(types.ApplyPatchType), r.Header.Get("Content-Type"))
+					require.Equal(t, "All", r.URL.Query().Get("dryRun"))
+					switch path, method := r.URL.Path, r.Method; {
+					case method == http.MethodPatch && path == deployPath && testCase.statusCode == http.StatusOK:
+						data, err := runtime.Encode(unstructured.NewJSONFallbackEncoder(codec), deploymentApplied)
+						require.NoError(t, err)
+						bodyRC := io.NopCloser(bytes.NewReader(data))
+						return &http.Response{StatusCode: http.StatusOK, Header: pkgtesting.DefaultHeaders(), Body: bodyRC}, nil
+					case method == http.MethodPatch && path == deployPath:
+						return &http.Response{StatusCode: testCase.statusCode, Header: pkgtesting.DefaultHeaders(), Body: io.NopCloser(bytes.NewReader(nil))}, nil
+					default:
+						t.Logf("unexpected request: %#v\n%#v", r.URL, r)
+						return nil, errors.New("unexpected request")
+					}
+				}),
+			}
+			infoFetcher, err := DefaultInfoFetcherBuilder(tf)
+			require.NoError(t, err)
+
+			task := &DiffTask{
+				FieldManager: "test",
+				Prune:        testCase.prune,
+				InfoFetcher:  infoFetcher,
+				Objects:      testCase.resources,
+				Filters:      testCase.filters,
+				RemoteGetter: &testRemoteGetter{objects: testCase.remoteObjects},
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, 1)
+			diffEvent := state.SentEvents[0]
+			t.Log(diffEvent)
+			require.Equal(t, event.TypeDiff, diffEvent.Type)
+			assert.Equal(t, testCase.expectedAction, diffEvent.DiffInfo.Action)
+			if len(testCase.expectedError) > 0 {
+				require.ErrorContains(t, diffEvent.DiffInfo.Error, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, diffEvent.DiffInfo.Error)
+			if len(testCase.expectedDiff) == 0 {
+				assert.Empty(t, diffEvent.DiffInfo.Diff)
+			}
+			for _, line := range testCase.expectedDiff {
+				assert.Contains(t, diffEvent.DiffInfo.Diff, line)
+			}
+			assert.NotContains(t, diffEvent.DiffInfo.Diff, "resourceVersion")
+			assert.NotContains(t, diffEvent.DiffInfo.Diff, "status:")
+		})
+	}
+}
+
+type testRemoteGetter struct {
+	objects []*unstructured.Unstructured
+}
+
+func (g *testRemoteGetter) Get(_ context.Context, id pkgresource.ObjectMetadata) (*unstructured.Unstructured, error) {
+	for _, obj := range g.objects {
+		if pkgresource.ObjectMetadataFromUnstructured(obj) == id {
+			return obj, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// keep it to always check if testRemoteGetter implement correctly the RemoteResourceGetter interface
+var _ cache.RemoteResourceGetter = &testRemoteGetter{}
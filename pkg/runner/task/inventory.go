@@ -18,21 +18,49 @@ package task
 import (
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
+	"github.com/mia-platform/jpl/pkg/util"
 )
 
-// keep it to always check if InventoryTask implement correctly the Task interface
+const (
+	warningInventoryKept = "inventory has not been deleted because some of its objects have not been removed"
+)
+
+// keep it to always check if InventoryTask implement correctly the Task and Finalizer interfaces
 var _ runner.Task = &InventoryTask{}
+var _ runner.Describer = &InventoryTask{}
+var _ runner.Finalizer = &InventoryTask{}
 
-// InventoryTask is used for updating an inventory with the current state saved in the Manager
+// InventoryTask is used for updating an inventory with the current state saved in the Manager, or for removing it
+// from the remote server if Delete is true
 type InventoryTask struct {
 	Manager *inventory.Manager
 	DryRun  bool
+	Delete  bool
+}
+
+// Kind implement the runner.Describer interface
+func (t *InventoryTask) Kind() string {
+	if t.Delete {
+		return "inventory-delete"
+	}
+	return "inventory"
+}
+
+// ObjectsCount implement the runner.Describer interface
+func (t *InventoryTask) ObjectsCount() int {
+	return 0
 }
 
 // Run implement the runner.Task interface
 func (t *InventoryTask) Run(state runner.State) {
-	ctx := state.GetContext()
+	ctx := retry.ContextWithNotifyFunc(state.GetContext(), func(info event.RetryInfo) {
+		state.SendEvent(event.Event{Type: event.TypeRetry, RetryInfo: info})
+	})
+	ctx = util.ContextWithWarningFunc(ctx, func(message string) {
+		state.SendEvent(warningEvent(nil, message))
+	})
 	state.SendEvent(event.Event{
 		Type: event.TypeInventory,
 		InventoryInfo: event.InventoryInfo{
@@ -40,7 +68,19 @@ func (t *InventoryTask) Run(state runner.State) {
 		},
 	})
 
-	if err := t.Manager.SaveCurrentInventoryState(ctx, t.DryRun); err != nil {
+	var err error
+	switch {
+	case t.Delete && t.Manager.CanDeleteRemoteInventory():
+		err = t.Manager.DeleteRemoteInventoryIfPossible(ctx, t.DryRun)
+	case t.Delete:
+		// the objects that have not been removed must be tracked for deleting them in a future run
+		state.SendEvent(warningEvent(nil, warningInventoryKept))
+		err = t.Manager.SaveCurrentInventoryState(ctx, t.DryRun)
+	default:
+		err = t.Manager.SaveCurrentInventoryState(ctx, t.DryRun)
+	}
+
+	if err != nil {
 		state.SendEvent(event.Event{
 			Type: event.TypeInventory,
 			InventoryInfo: event.InventoryInfo{
@@ -58,3 +98,9 @@ func (t *InventoryTask) Run(state runner.State) {
 		},
 	})
 }
+
+// IsFinalizer implement the runner.Finalizer interface, the inventory must always be updated for keeping track
+// of the objects applied before a timeout or a cancellation
+func (t *InventoryTask) IsFinalizer() bool {
+	return true
+}
@@ -16,18 +16,25 @@
 package task
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	fakerest "k8s.io/client-go/rest/fake"
 
 	"github.com/mia-platform/jpl/pkg/event"
 	"github.com/mia-platform/jpl/pkg/inventory"
 	fakeinventory "github.com/mia-platform/jpl/pkg/inventory/fake"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
 	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
 )
@@ -78,6 +85,7 @@ func TestInventoryTaskRun(t *testing.T) {
 		inventory      *fakeinventory.Inventory
 		expectedEvents []event.Event
 		dryRun         bool
+		delete         bool
 	}{
 		"update inventory without error": {
 			inventory: &fakeinventory.Inventory{},
@@ -139,6 +147,45 @@ func TestInventoryTaskRun(t *testing.T) {
 				},
 			},
 		},
+		"delete inventory without error": {
+			inventory: &fakeinventory.Inventory{},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusSuccessful,
+					},
+				},
+			},
+			delete: true,
+		},
+		"delete inventory with error": {
+			inventory: &fakeinventory.Inventory{
+				DeleteErr: errors.New("error during delete"),
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusPending,
+					},
+				},
+				{
+					Type: event.TypeInventory,
+					InventoryInfo: event.InventoryInfo{
+						Status: event.StatusFailed,
+						Error:  errors.New("error during delete"),
+					},
+				},
+			},
+			delete: true,
+		},
 	}
 
 	for testName, testCase := range testCases {
@@ -148,6 +195,7 @@ func TestInventoryTaskRun(t *testing.T) {
 			task := &InventoryTask{
 				Manager: inventory.NewManager(testCase.inventory, nil),
 				DryRun:  testCase.dryRun,
+				Delete:  testCase.delete,
 			}
 
 			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
@@ -162,3 +210,97 @@ func TestInventoryTaskRun(t *testing.T) {
 		})
 	}
 }
+
+func TestInventoryTaskRetry(t *testing.T) {
+	t.Parallel()
+
+	conflictErr := apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "test", errors.New("the object has been modified"))
+	saves := 0
+	store := &fakeinventory.Inventory{
+		SaveFunc: func(context.Context, bool) error {
+			saves++
+			if saves == 1 {
+				return conflictErr
+			}
+			return nil
+		},
+	}
+
+	manager := inventory.NewManager(store, nil)
+	manager.RetryPolicy = &retry.Policy{MaxAttempts: 2, InitialDelay: time.Millisecond}
+	task := &InventoryTask{Manager: manager}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	expectedEvents := []event.Event{
+		{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusPending}},
+		{
+			Type: event.TypeRetry,
+			RetryInfo: event.RetryInfo{
+				Operation:   "inventory save",
+				Attempt:     1,
+				MaxAttempts: 2,
+				Delay:       time.Millisecond,
+				Error:       conflictErr,
+			},
+		},
+		{Type: event.TypeInventory, InventoryInfo: event.InventoryInfo{Status: event.StatusSuccessful}},
+	}
+	assert.Equal(t, expectedEvents, state.SentEvents)
+	assert.Equal(t, 2, saves)
+}
+
+func TestInventoryTaskWarnings(t *testing.T) {
+	t.Parallel()
+
+	warning := "unknown field \"data.extra\""
+	tf := pkgtesting.NewTestClientFactory().WithNamespace("test")
+	tf.Client = &fakerest.RESTClient{
+		Client: fakerest.CreateHTTPClient(func(r *http.Request) (*http.Response, error) {
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			header := pkgtesting.DefaultHeaders()
+			header.Add("Warning", fmt.Sprintf("299 - %q", warning))
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(bytes.NewReader(data))}, nil
+		}),
+	}
+	configmap, err := inventory.NewConfigMapStore(tf, "test", "test", "jpl")
+	require.NoError(t, err)
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.TypeInventory,
+			InventoryInfo: event.InventoryInfo{
+				Status: event.StatusPending,
+			},
+		},
+		{
+			Type: event.TypeWarning,
+			WarningInfo: event.WarningInfo{
+				Message: warning,
+			},
+		},
+		{
+			Type: event.TypeInventory,
+			InventoryInfo: event.InventoryInfo{
+				Status: event.StatusSuccessful,
+			},
+		},
+	}
+	task := &InventoryTask{
+		Manager: inventory.NewManager(configmap, nil),
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, len(expectedEvents))
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+	}
+}
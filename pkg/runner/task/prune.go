@@ -17,19 +17,28 @@ package task
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/resource"
+	"github.com/mia-platform/jpl/pkg/retry"
 	"github.com/mia-platform/jpl/pkg/runner"
+	"github.com/mia-platform/jpl/pkg/telemetry"
+	"github.com/mia-platform/jpl/pkg/util"
 )
 
 // keep it to always check if PruneTask implement correctly the Task interface
 var _ runner.Task = &PruneTask{}
+var _ runner.Describer = &PruneTask{}
 
 // PruneTask is the task used for removing Objects from the remote server
 type PruneTask struct {
@@ -38,29 +47,104 @@ type PruneTask struct {
 	Client       dynamic.Interface
 	Mapper       meta.RESTMapper
 
+	// InventoryID if set will prevent the deletion of objects owned by another inventory
+	InventoryID string
+	// FailFast will stop the deletion of all the Objects if an object has failed to be applied during the run
+	FailFast bool
+	// Metrics if set is updated with the results and the api-server latency of the Objects
+	Metrics *telemetry.Metrics
+	// RetryPolicy if set is used for retrying the deletions that have failed for transient errors
+	RetryPolicy *retry.Policy
+
 	Objects []*unstructured.Unstructured
 }
 
+// Kind implement the runner.Describer interface
+func (t *PruneTask) Kind() string {
+	return "prune"
+}
+
+// ObjectsCount implement the runner.Describer interface
+func (t *PruneTask) ObjectsCount() int {
+	return len(t.Objects)
+}
+
 // Run implement the runner.Task interface
 func (t *PruneTask) Run(state runner.State) {
 	ctx := state.GetContext()
+	// the objects are not removed from the remote server, so they must be kept in the inventory and not waited
+	// for their deletion
+	if t.FailFast && state.HasFailures() {
+		for _, obj := range t.Objects {
+			t.Metrics.ObserveObject(telemetry.OperationPrune, event.StatusSkipped)
+			state.SendEvent(keptEvent(obj, ErrFailFast))
+		}
+		return
+	}
+
 	for _, obj := range t.Objects {
 		state.SendEvent(pruneEvent(event.StatusPending, obj, nil))
-		if err := pruneObject(ctx, t.Mapper, t.Client, obj, t.DryRun); err != nil {
-			// if the object is already missing don't return an error
-			if !apierrors.IsNotFound(err) {
-				state.SendEvent(pruneEvent(event.StatusFailed, obj, err))
-			}
-			continue
+		for _, e := range t.observeObject(ctx, obj) {
+			state.SendEvent(e)
+		}
+	}
+}
+
+// observeObject return the warnings, the retries and the final event of processObject for obj, tracing it in its
+// own span and updating the metrics with its status
+func (t *PruneTask) observeObject(ctx context.Context, obj *unstructured.Unstructured) []event.Event {
+	ctx, span := telemetry.StartSpan(ctx, "prune object", telemetry.ObjectAttributes(obj)...)
+	var events []event.Event
+	ctx = util.ContextWithWarningFunc(ctx, func(message string) {
+		events = append(events, warningEvent(obj, message))
+	})
+	ctx = retry.ContextWithNotifyFunc(ctx, func(info event.RetryInfo) {
+		events = append(events, retryEvent(obj, info))
+	})
+	finalEvent := t.processObject(ctx, obj)
+
+	telemetry.EndSpan(span, finalEvent.PruneInfo.Error)
+	t.Metrics.ObserveObject(telemetry.OperationPrune, finalEvent.PruneInfo.Status)
+	return append(events, finalEvent)
+}
+
+// processObject remove obj from the remote server if possible and return the event with the final status
+func (t *PruneTask) processObject(ctx context.Context, obj *unstructured.Unstructured) event.Event {
+	// objects annotated for preventing their deletion are only removed from the inventory, releasing their
+	// ownership so they can be adopted by another inventory
+	if resource.IsDeletionPrevented(obj) {
+		err := t.RetryPolicy.Do(ctx, "release", func() error {
+			return t.releaseObject(ctx, obj)
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return pruneEvent(event.StatusFailed, obj, err)
 		}
+		return pruneEvent(event.StatusSkipped, obj, nil)
+	}
 
-		state.SendEvent(pruneEvent(event.StatusSuccessful, obj, nil))
+	// objects owned by another inventory are only removed from the current one
+	if err := canPrune(obj, t.InventoryID); err != nil {
+		return pruneEvent(event.StatusSkipped, obj, err)
 	}
+
+	start := time.Now()
+	err := t.RetryPolicy.Do(ctx, "prune", func() error {
+		return pruneObject(ctx, t.Mapper, t.Client, obj, t.DryRun)
+	})
+	t.Metrics.ObserveRequest(telemetry.OperationPrune, time.Since(start))
+
+	// if the object is already missing don't return an error
+	if err != nil && !apierrors.IsNotFound(err) {
+		return pruneEvent(event.StatusFailed, obj, err)
+	}
+
+	return pruneEvent(event.StatusSuccessful, obj, nil)
 }
 
-// pruneEvent create an Event for a prune action with the passed object and status
+// pruneEvent create an Event for a prune action with the passed object and status, failed events are classified
+// using err
 func pruneEvent(status event.Status, obj *unstructured.Unstructured, err error) event.Event {
-	return event.Event{
+	e := event.Event{
 		Type: event.TypePrune,
 		PruneInfo: event.PruneInfo{
 			Status: status,
@@ -68,6 +152,59 @@ func pruneEvent(status event.Status, obj *unstructured.Unstructured, err error)
 			Error:  err,
 		},
 	}
+
+	if status == event.StatusFailed {
+		e.PruneInfo.Reason = event.ReasonForError(err)
+		e.PruneInfo.Causes = event.CausesForError(err)
+	}
+	return e
+}
+
+// keptEvent create an Event for a prune action that has not been done, keeping obj in the inventory for pruning
+// it in a future run, err contains the reason
+func keptEvent(obj *unstructured.Unstructured, err error) event.Event {
+	e := pruneEvent(event.StatusSkipped, obj, err)
+	e.PruneInfo.Kept = true
+	return e
+}
+
+// canPrune return an error if obj cannot be deleted because is not owned by inventoryID
+func canPrune(obj *unstructured.Unstructured, inventoryID string) error {
+	if inventoryID == "" {
+		return nil
+	}
+
+	return inventory.CanPrune(obj, inventoryID)
+}
+
+// releaseObject remove from the remote obj the annotation with its owning inventory, if it is owned by the
+// current one
+func (t *PruneTask) releaseObject(ctx context.Context, obj *unstructured.Unstructured) error {
+	if t.InventoryID == "" || inventory.OwnerOf(obj) != t.InventoryID {
+		return nil
+	}
+
+	mapping, err := t.Mapper.RESTMapping(obj.GroupVersionKind().GroupKind())
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{inventory.OwningInventoryAnnotation: nil},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	opts := metav1.PatchOptions{FieldManager: t.FieldManager}
+	if t.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	_, err = t.Client.Resource(mapping.Resource).Namespace(obj.GetNamespace()).
+		Patch(ctx, obj.GetName(), types.MergePatchType, patch, opts)
+	return err
 }
 
 // pruneObject will delete the passed objMeta object in the remote cluster
@@ -24,8 +24,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/mia-platform/jpl/pkg/event"
+	"github.com/mia-platform/jpl/pkg/inventory"
+	"github.com/mia-platform/jpl/pkg/resource"
 	"github.com/mia-platform/jpl/pkg/runner"
 	pkgtesting "github.com/mia-platform/jpl/pkg/testing"
 )
@@ -128,3 +132,184 @@ func TestPruneAction(t *testing.T) {
 		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
 	}
 }
+
+func TestPruneDeletionPrevented(t *testing.T) {
+	t.Parallel()
+
+	inventoryID := "test/inventory"
+	testCases := map[string]struct {
+		annotations   map[string]string
+		expectedOwner string
+	}{
+		"detached object": {
+			annotations: map[string]string{resource.LifecycleDeletionAnnotation: resource.PreventDeletion},
+		},
+		"object kept on remove": {
+			annotations: map[string]string{resource.OnRemoveAnnotation: resource.OnRemoveKeep},
+		},
+		"detached object is released by its owner": {
+			annotations: map[string]string{
+				resource.LifecycleDeletionAnnotation: resource.PreventDeletion,
+				inventory.OwningInventoryAnnotation:  inventoryID,
+			},
+		},
+		"detached object owned by another inventory is not released": {
+			annotations: map[string]string{
+				resource.LifecycleDeletionAnnotation: resource.PreventDeletion,
+				inventory.OwningInventoryAnnotation:  "test/other",
+			},
+			expectedOwner: "test/other",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			tf := pkgtesting.NewTestClientFactory()
+
+			deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+			deployment.SetAnnotations(testCase.annotations)
+			require.NoError(t, tf.FakeDynamicClient.Tracker().Add(deployment))
+
+			mapper, err := tf.ToRESTMapper()
+			require.NoError(t, err)
+
+			task := &PruneTask{
+				InventoryID: inventoryID,
+				Objects: []*unstructured.Unstructured{
+					deployment,
+				},
+				Client: tf.FakeDynamicClient,
+				Mapper: mapper,
+			}
+
+			expectedEvents := []event.Event{
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Status: event.StatusPending,
+						Object: deployment,
+					},
+				},
+				{
+					Type: event.TypePrune,
+					PruneInfo: event.PruneInfo{
+						Status: event.StatusSkipped,
+						Object: deployment,
+					},
+				},
+			}
+
+			withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+			defer cancel()
+			state := &runner.FakeState{Context: withTimeout}
+
+			task.Run(state)
+			require.Len(t, state.SentEvents, len(expectedEvents))
+			for idx, expectedEvent := range expectedEvents {
+				assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+				assert.False(t, state.SentEvents[idx].PruneInfo.Kept)
+			}
+
+			gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+			remote, err := tf.FakeDynamicClient.Tracker().Get(gvr, deployment.GetNamespace(), deployment.GetName())
+			require.NoError(t, err)
+			remoteObj, ok := remote.(*unstructured.Unstructured)
+			require.True(t, ok)
+			assert.Equal(t, testCase.expectedOwner, inventory.OwnerOf(remoteObj))
+		})
+	}
+}
+
+func TestPruneOwnedByAnotherInventory(t *testing.T) {
+	t.Parallel()
+
+	tf := pkgtesting.NewTestClientFactory()
+
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	inventory.SetOwner(deployment, "test/other")
+	require.NoError(t, tf.FakeDynamicClient.Tracker().Add(deployment))
+
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+
+	task := &PruneTask{
+		InventoryID: "test/inventory",
+		Objects: []*unstructured.Unstructured{
+			deployment,
+		},
+		Client: tf.FakeDynamicClient,
+		Mapper: mapper,
+	}
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.TypePrune,
+			PruneInfo: event.PruneInfo{
+				Status: event.StatusPending,
+				Object: deployment,
+			},
+		},
+		{
+			Type: event.TypePrune,
+			PruneInfo: event.PruneInfo{
+				Status: event.StatusSkipped,
+				Object: deployment,
+				Error:  &inventory.OwnershipError{Owner: "test/other", Inventory: "test/inventory"},
+			},
+		},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, len(expectedEvents))
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+	}
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	_, err = tf.FakeDynamicClient.Tracker().Get(gvr, deployment.GetNamespace(), deployment.GetName())
+	assert.NoError(t, err)
+}
+
+func TestPruneFailFast(t *testing.T) {
+	t.Parallel()
+
+	tf := pkgtesting.NewTestClientFactory()
+
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	require.NoError(t, tf.FakeDynamicClient.Tracker().Add(deployment))
+
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+
+	task := &PruneTask{
+		FailFast: true,
+		Objects: []*unstructured.Unstructured{
+			deployment,
+		},
+		Client: tf.FakeDynamicClient,
+		Mapper: mapper,
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+	state := &runner.FakeState{
+		Context:       withTimeout,
+		FailedObjects: sets.New(resource.ObjectMetadata{Kind: "ConfigMap", Name: "other", Namespace: "test"}),
+	}
+
+	task.Run(state)
+	require.Len(t, state.SentEvents, 1)
+	assert.Equal(t, keptEvent(deployment, ErrFailFast).String(), state.SentEvents[0].String())
+	assert.True(t, state.SentEvents[0].PruneInfo.Kept)
+	assert.ErrorIs(t, state.SentEvents[0].PruneInfo.Error, ErrFailFast)
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	_, err = tf.FakeDynamicClient.Tracker().Get(gvr, deployment.GetNamespace(), deployment.GetName())
+	assert.NoError(t, err)
+}
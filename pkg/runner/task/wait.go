@@ -17,6 +17,7 @@ package task
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -27,21 +28,40 @@ import (
 	"github.com/mia-platform/jpl/pkg/poller"
 	"github.com/mia-platform/jpl/pkg/resource"
 	"github.com/mia-platform/jpl/pkg/runner"
+	"github.com/mia-platform/jpl/pkg/telemetry"
 )
 
 // keep it to always check if WaitTask implement correctly the Task interface
 var _ runner.Task = &WaitTask{}
+var _ runner.Describer = &WaitTask{}
 
-// WaitTask is the task used for removing Objects from the remote server
+// WaitTask is the task used for waiting the Objects to reach their current status on the remote server, or to
+// be removed from it if Deletion is true and the Poller implement the poller.DeletionPoller interface
 type WaitTask struct {
-	Objects []*unstructured.Unstructured
-	Poller  poller.StatusPoller
-	Mapper  meta.RESTMapper
-	Manager *inventory.Manager
+	Objects  []*unstructured.Unstructured
+	Poller   poller.StatusPoller
+	Mapper   meta.RESTMapper
+	Manager  *inventory.Manager
+	Deletion bool
+	// Metrics if set is updated with the time waited for every object
+	Metrics *telemetry.Metrics
 
 	objectsToWatch sets.Set[resource.ObjectMetadata]
 }
 
+// Kind implement the runner.Describer interface
+func (t *WaitTask) Kind() string {
+	if t.Deletion {
+		return "wait-deletion"
+	}
+	return "wait"
+}
+
+// ObjectsCount implement the runner.Describer interface
+func (t *WaitTask) ObjectsCount() int {
+	return len(t.Objects)
+}
+
 // Run implement the runner.Task interface
 func (t *WaitTask) Run(state runner.State) {
 	ctx, cancel := context.WithCancel(state.GetContext())
@@ -62,7 +82,19 @@ func (t *WaitTask) Run(state runner.State) {
 		return
 	}
 
-	pollerCh := t.Poller.Start(ctx, pollerObjects)
+	start := time.Now()
+	var pollerCh <-chan event.Event
+	if t.Deletion {
+		deletionPoller, ok := t.Poller.(poller.DeletionPoller)
+		if !ok {
+			cancel()
+			return
+		}
+		pollerCh = deletionPoller.StartDeletion(ctx, pollerObjects)
+	} else {
+		pollerCh = t.Poller.Start(ctx, pollerObjects)
+	}
+
 	resetMapper := false
 	for {
 		msg, open := <-pollerCh
@@ -80,6 +112,7 @@ func (t *WaitTask) Run(state runner.State) {
 
 		if msg.Type == event.TypeStatusUpdate && msg.StatusUpdateInfo.Status == event.StatusSuccessful {
 			t.objectsToWatch.Delete(msg.StatusUpdateInfo.ObjectMetadata)
+			t.Metrics.ObserveWait(t.Deletion, time.Since(start))
 			if resource.MetadataIsCRD(msg.StatusUpdateInfo.ObjectMetadata) {
 				resetMapper = true
 			}
@@ -112,3 +112,54 @@ func TestWaitTask(t *testing.T) {
 		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
 	}
 }
+
+func TestWaitDeletionTask(t *testing.T) {
+	t.Parallel()
+
+	deployment := pkgtesting.UnstructuredFromFile(t, deploymentFilename)
+	namespace := pkgtesting.UnstructuredFromFile(t, namespaceFilename)
+
+	task := &WaitTask{
+		Objects: []*unstructured.Unstructured{
+			deployment,
+			namespace,
+		},
+		Poller:   &poller.FakePoller{},
+		Deletion: true,
+	}
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.TypeStatusUpdate,
+			StatusUpdateInfo: event.StatusUpdateInfo{
+				Status:         event.StatusSuccessful,
+				Message:        "Resource has been removed",
+				ObjectMetadata: resource.ObjectMetadataFromUnstructured(deployment),
+			},
+		},
+		{
+			Type: event.TypeStatusUpdate,
+			StatusUpdateInfo: event.StatusUpdateInfo{
+				Status:         event.StatusSuccessful,
+				Message:        "Resource has been removed",
+				ObjectMetadata: resource.ObjectMetadataFromUnstructured(namespace),
+			},
+		},
+	}
+
+	withTimeout, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	state := &runner.FakeState{Context: withTimeout}
+
+	go func() {
+		task.Run(state)
+		cancel()
+	}()
+
+	<-withTimeout.Done()
+	require.NotEqual(t, withTimeout.Err(), context.DeadlineExceeded)
+	assert.Len(t, state.SentEvents, len(expectedEvents))
+	for idx, expectedEvent := range expectedEvents {
+		assert.Equal(t, expectedEvent.String(), state.SentEvents[idx].String())
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var _ trace.TracerProvider = &FakeTracerProvider{}
+
+// FakeTracerProvider is used to test the spans created by the code, it will keep in memory all the spans started
+// by its tracers
+type FakeTracerProvider struct {
+	embedded.TracerProvider
+
+	lock  sync.Mutex
+	spans []*FakeSpan
+}
+
+// Tracer implement trace.TracerProvider
+func (p *FakeTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &fakeTracer{provider: p}
+}
+
+// Spans return a copy of all the spans started until now, in the order they have been started
+func (p *FakeTracerProvider) Spans() []FakeSpan {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	spans := make([]FakeSpan, 0, len(p.spans))
+	for _, span := range p.spans {
+		spans = append(spans, FakeSpan{
+			Name:       span.Name,
+			Parent:     span.Parent,
+			Attributes: span.Attributes,
+			Err:        span.Err,
+			Ended:      span.Ended,
+		})
+	}
+	return spans
+}
+
+type fakeTracer struct {
+	embedded.Tracer
+
+	provider *FakeTracerProvider
+}
+
+// Start implement trace.Tracer
+func (t *fakeTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	span := &FakeSpan{
+		Name:       name,
+		Attributes: config.Attributes(),
+		provider:   t.provider,
+	}
+	if parent, ok := trace.SpanFromContext(ctx).(*FakeSpan); ok {
+		span.Parent = parent.Name
+	}
+
+	t.provider.lock.Lock()
+	defer t.provider.lock.Unlock()
+	t.provider.spans = append(t.provider.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// FakeSpan is a span started by a FakeTracerProvider
+type FakeSpan struct {
+	noop.Span
+
+	Name string
+	// Parent is the name of the parent span if it has been started by the same FakeTracerProvider
+	Parent     string
+	Attributes []attribute.KeyValue
+	// Err is the last error recorded in the span
+	Err   error
+	Ended bool
+
+	provider *FakeTracerProvider
+}
+
+// End implement trace.Span
+func (s *FakeSpan) End(...trace.SpanEndOption) {
+	s.provider.lock.Lock()
+	defer s.provider.lock.Unlock()
+	s.Ended = true
+}
+
+// RecordError implement trace.Span
+func (s *FakeSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.provider.lock.Lock()
+	defer s.provider.lock.Unlock()
+	s.Err = err
+}
+
+// IsRecording implement trace.Span
+func (s *FakeSpan) IsRecording() bool {
+	return true
+}
+
+// TracerProvider implement trace.Span
+func (s *FakeSpan) TracerProvider() trace.TracerProvider {
+	return s.provider
+}
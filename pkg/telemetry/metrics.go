@@ -0,0 +1,111 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+const (
+	metricsNamespace = "jpl"
+
+	// OperationApply and OperationPrune are the values of the operation label of the metrics
+	OperationApply = "apply"
+	OperationPrune = "prune"
+
+	// WaitCurrent and WaitDeletion are the values of the wait label of the wait duration metric
+	WaitCurrent  = "current"
+	WaitDeletion = "deletion"
+)
+
+var statusLabels = map[event.Status]string{
+	event.StatusPending:    "pending",
+	event.StatusSuccessful: "successful",
+	event.StatusFailed:     "failed",
+	event.StatusSkipped:    "skipped",
+}
+
+// Metrics contains the Prometheus collectors updated during the runs of an Applier. All its methods can be called
+// on a nil Metrics without doing anything, so the metrics collection is optional.
+type Metrics struct {
+	objects         *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	waitDuration    *prometheus.HistogramVec
+}
+
+// NewMetrics create the collectors for the metrics and register them with registerer
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	metrics := &Metrics{
+		objects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "objects_total",
+			Help:      "Number of objects handled by the runs, partitioned by operation and final status.",
+		}, []string{"operation", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latency of the api-server requests made for applying and pruning objects.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		waitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Time waited for objects to reach their current status or to be removed.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"wait"}),
+	}
+
+	for _, collector := range []prometheus.Collector{metrics.objects, metrics.requestDuration, metrics.waitDuration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return metrics, nil
+}
+
+// ObserveObject count an object that has ended operation with status
+func (m *Metrics) ObserveObject(operation string, status event.Status) {
+	if m == nil {
+		return
+	}
+	m.objects.WithLabelValues(operation, statusLabels[status]).Inc()
+}
+
+// ObserveRequest record the duration of an api-server request made for operation
+func (m *Metrics) ObserveRequest(operation string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requestDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// ObserveWait record the time waited for an object to reach its current status, or its removal if deletion is true
+func (m *Metrics) ObserveWait(deletion bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	wait := WaitCurrent
+	if deletion {
+		wait = WaitDeletion
+	}
+	m.waitDuration.WithLabelValues(wait).Observe(duration.Seconds())
+}
@@ -0,0 +1,73 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mia-platform/jpl/pkg/event"
+)
+
+func TestNewMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+	metrics, err := NewMetrics(registry)
+	require.NoError(t, err)
+	require.NotNil(t, metrics)
+
+	// the same collectors cannot be registered twice
+	_, err = NewMetrics(registry)
+	assert.Error(t, err)
+}
+
+func TestMetricsObserve(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := NewMetrics(prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+
+	metrics.ObserveObject(OperationApply, event.StatusSuccessful)
+	metrics.ObserveObject(OperationApply, event.StatusSuccessful)
+	metrics.ObserveObject(OperationApply, event.StatusFailed)
+	metrics.ObserveObject(OperationPrune, event.StatusSkipped)
+	metrics.ObserveRequest(OperationApply, 100*time.Millisecond)
+	metrics.ObserveWait(false, 2*time.Second)
+	metrics.ObserveWait(true, time.Second)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(metrics.objects.WithLabelValues(OperationApply, "successful")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(metrics.objects.WithLabelValues(OperationApply, "failed")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(metrics.objects.WithLabelValues(OperationPrune, "skipped")), 0)
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.requestDuration))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.waitDuration))
+}
+
+func TestNilMetrics(t *testing.T) {
+	t.Parallel()
+
+	var metrics *Metrics
+	assert.NotPanics(t, func() {
+		metrics.ObserveObject(OperationApply, event.StatusSuccessful)
+		metrics.ObserveRequest(OperationPrune, time.Second)
+		metrics.ObserveWait(true, time.Second)
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TracerName is the name of the tracer used for creating all the spans of the library
+const TracerName = "github.com/mia-platform/jpl"
+
+// StartSpan start a new span called name as a child of the span contained in ctx, using the same TracerProvider
+// of its parent. If ctx does not contain any span the TracerProvider is a no-op one, so the spans are created only
+// if the run has been started with a configured TracerProvider.
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan end span setting its status to error if err is not nil
+func EndSpan(span trace.Span, err error) {
+	recordError(span, err)
+	span.End()
+}
+
+// RecordError set the status of the span contained in ctx to error if err is not nil
+func RecordError(ctx context.Context, err error) {
+	recordError(trace.SpanFromContext(ctx), err)
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// ObjectAttributes return the span attributes identifying obj
+func ObjectAttributes(obj *unstructured.Unstructured) []attribute.KeyValue {
+	gvk := obj.GroupVersionKind()
+	return []attribute.KeyValue{
+		attribute.String("k8s.object.group", gvk.Group),
+		attribute.String("k8s.object.kind", gvk.Kind),
+		attribute.String("k8s.object.namespace", obj.GetNamespace()),
+		attribute.String("k8s.object.name", obj.GetName()),
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStartSpanWithoutParent(t *testing.T) {
+	t.Parallel()
+
+	ctx, span := StartSpan(t.Context(), "test")
+	assert.False(t, span.IsRecording())
+	assert.False(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+	EndSpan(span, errors.New("error"))
+}
+
+func TestStartSpanWithParent(t *testing.T) {
+	t.Parallel()
+
+	provider := &FakeTracerProvider{}
+	ctx, root := provider.Tracer(TracerName).Start(t.Context(), "root")
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("test")
+	obj.SetName("nginx")
+
+	ctx, child := StartSpan(ctx, "child", ObjectAttributes(obj)...)
+	assert.True(t, child.IsRecording())
+
+	_, grandChild := StartSpan(ctx, "grand child")
+	EndSpan(grandChild, nil)
+	EndSpan(child, errors.New("child error"))
+
+	RecordError(ctx, errors.New("recorded error"))
+	root.End()
+
+	spans := provider.Spans()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "root", spans[0].Name)
+	assert.Empty(t, spans[0].Parent)
+	assert.True(t, spans[0].Ended)
+
+	assert.Equal(t, "child", spans[1].Name)
+	assert.Equal(t, "root", spans[1].Parent)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("k8s.object.group", "apps"),
+		attribute.String("k8s.object.kind", "Deployment"),
+		attribute.String("k8s.object.namespace", "test"),
+		attribute.String("k8s.object.name", "nginx"),
+	}, spans[1].Attributes)
+	assert.EqualError(t, spans[1].Err, "recorded error")
+	assert.True(t, spans[1].Ended)
+
+	assert.Equal(t, "grand child", spans[2].Name)
+	assert.Equal(t, "child", spans[2].Parent)
+	assert.NoError(t, spans[2].Err)
+	assert.True(t, spans[2].Ended)
+}
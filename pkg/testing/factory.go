@@ -110,7 +110,10 @@ func (f *TestClientFactory) DynamicClient() (dynamic.Interface, error) {
 // KubernetesClientSet reimplement the method for returning only selected kubernetes clients with fake client
 func (f *TestClientFactory) KubernetesClientSet() (kubernetes.Interface, error) {
 	fakeClient := f.Client.(*fakerest.RESTClient)
-	clientset := kubernetes.NewForConfigOrDie(f.clientConfig)
+	// forward the warnings to the context of the requests like the clients of util.NewFactory
+	config := rest.CopyConfig(f.clientConfig)
+	config.WarningHandlerWithContext = util.WarningHandler{}
+	clientset := kubernetes.NewForConfigOrDie(config)
 
 	clientset.CoreV1().RESTClient().(*rest.RESTClient).Client = fakeClient.Client
 
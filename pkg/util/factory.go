@@ -41,7 +41,8 @@ type ClientFactory interface {
 	KubernetesClientSet() (kubernetes.Interface, error)
 }
 
-// NewFactory return a CleintFactory implementation
+// NewFactory return a CleintFactory implementation, the clients created by it will forward the warnings returned
+// by the api-server to the WarningFunc contained in the context of the request
 func NewFactory(clientGetter genericclioptions.RESTClientGetter) ClientFactory {
 	return &factoryImplementation{
 		delegate: clientGetter,
@@ -79,7 +80,7 @@ func (f *factoryImplementation) DynamicClient() (dynamic.Interface, error) {
 		return nil, err
 	}
 
-	return dynamic.NewForConfig(clientConfig)
+	return dynamic.NewForConfig(withWarningHandler(clientConfig))
 }
 
 // UnstructuredClientForMapping return a RESTClient that can be used for the Unstructured object described by mapping
@@ -89,6 +90,7 @@ func (f *factoryImplementation) UnstructuredClientForMapping(mapping *meta.RESTM
 	if err != nil {
 		return nil, err
 	}
+	cfg = withWarningHandler(cfg)
 	if err := rest.SetKubernetesDefaults(cfg); err != nil {
 		return nil, err
 	}
@@ -109,5 +111,5 @@ func (f *factoryImplementation) KubernetesClientSet() (kubernetes.Interface, err
 		return nil, err
 	}
 
-	return kubernetes.NewForConfig(clientConfig)
+	return kubernetes.NewForConfig(withWarningHandler(clientConfig))
 }
@@ -0,0 +1,65 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	"k8s.io/client-go/rest"
+)
+
+// warningCode is the code of the warnings headers returned by the api-server, see RFC 7234
+const warningCode = 299
+
+// WarningFunc is called with the message of every warning found while handling a request
+type WarningFunc func(message string)
+
+type warningFuncKey struct{}
+
+// ContextWithWarningFunc return a copy of ctx that will forward to warn all the warnings found during the requests
+// made with it, including the ones returned by the api-server to the clients created by ClientFactory
+func ContextWithWarningFunc(ctx context.Context, warn WarningFunc) context.Context {
+	return context.WithValue(ctx, warningFuncKey{}, warn)
+}
+
+// Warn forward message to the WarningFunc contained in ctx, the warning is discarded if ctx doesn't contain one
+func Warn(ctx context.Context, message string) {
+	if warn, ok := ctx.Value(warningFuncKey{}).(WarningFunc); ok && warn != nil {
+		warn(message)
+	}
+}
+
+var _ rest.WarningHandlerWithContext = WarningHandler{}
+
+// WarningHandler is a rest.WarningHandlerWithContext that forward the warning headers returned by the api-server
+// to the WarningFunc contained in the context of the request
+type WarningHandler struct{}
+
+// HandleWarningHeaderWithContext implement rest.WarningHandlerWithContext
+func (WarningHandler) HandleWarningHeaderWithContext(ctx context.Context, code int, _ string, message string) {
+	if code != warningCode || len(message) == 0 {
+		return
+	}
+	Warn(ctx, message)
+}
+
+// withWarningHandler return a copy of config that will use WarningHandler for the warnings returned by the api-server
+func withWarningHandler(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.WarningHandler = nil
+	config.WarningHandlerWithContext = WarningHandler{}
+	return config
+}
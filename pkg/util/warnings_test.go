@@ -0,0 +1,88 @@
+// Copyright Mia srl
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestWarningHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code             int
+		message          string
+		withFunc         bool
+		expectedWarnings []string
+	}{
+		"warning is forwarded": {
+			code:             299,
+			message:          "apps/v1beta1 Deployment is deprecated",
+			withFunc:         true,
+			expectedWarnings: []string{"apps/v1beta1 Deployment is deprecated"},
+		},
+		"other codes are ignored": {
+			code:     199,
+			message:  "miscellaneous warning",
+			withFunc: true,
+		},
+		"empty messages are ignored": {
+			code:     299,
+			withFunc: true,
+		},
+		"context without WarningFunc": {
+			code:    299,
+			message: "apps/v1beta1 Deployment is deprecated",
+		},
+	}
+
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var warnings []string
+			ctx := t.Context()
+			if testCase.withFunc {
+				ctx = ContextWithWarningFunc(ctx, func(message string) {
+					warnings = append(warnings, message)
+				})
+			}
+
+			WarningHandler{}.HandleWarningHeaderWithContext(ctx, testCase.code, "-", testCase.message)
+			assert.Equal(t, testCase.expectedWarnings, warnings)
+		})
+	}
+}
+
+func TestWithWarningHandler(t *testing.T) {
+	t.Parallel()
+
+	config := &rest.Config{Host: "https://example.com", WarningHandler: rest.NoWarnings{}}
+	configWithHandler := withWarningHandler(config)
+
+	assert.Nil(t, configWithHandler.WarningHandler)
+	assert.Equal(t, WarningHandler{}, configWithHandler.WarningHandlerWithContext)
+	assert.Equal(t, config.Host, configWithHandler.Host)
+
+	// the original config is not modified
+	assert.Equal(t, rest.NoWarnings{}, config.WarningHandler)
+	assert.Nil(t, config.WarningHandlerWithContext)
+	assert.NotSame(t, config, configWithHandler)
+
+}